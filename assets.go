@@ -0,0 +1,283 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// assetEncodings are the precompressed variants served by AssetServer, in
+// order of preference, along with the file extensions they are stored with.
+var assetEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// defaultAssetServer is the asset server used by the asset template function.
+var defaultAssetServer atomic.Pointer[AssetServer]
+
+func init() {
+	RegisterTemplateFuncs(template.FuncMap{
+		"asset": func(name string) string {
+			if s := defaultAssetServer.Load(); s != nil {
+				return s.Path(name)
+			}
+			return name
+		},
+	})
+}
+
+// RegisterAssetServer makes the asset template function resolve asset names
+// through s, so that templates can refer to fingerprinted assets:
+//
+//	<script src="{{asset "app.js"}}"></script>
+//
+// renders as
+//
+//	<script src="/static/app.5d41402abc4b2a76.js"></script>
+//
+// Until an asset server is registered, the function returns the name
+// unchanged.
+func RegisterAssetServer(s *AssetServer) {
+	defaultAssetServer.Store(s)
+}
+
+// asset is a single static file served by AssetServer.
+type asset struct {
+	name       string
+	hashedName string
+	hash       string
+	modTime    time.Time
+
+	// encoded maps content codings to the names of precompressed variants.
+	encoded map[string]string
+}
+
+// AssetServer serves static files from an fs.FS with fingerprinted URLs.
+//
+// Content hashes of all the files are computed when the server is created,
+// and each file is served under both its own name and a fingerprinted name
+// with the hash inserted before the extension, e.g. app.js is also served as
+// app.5d41402abc4b2a76.js. Fingerprinted URLs never change content, so they
+// are served with an immutable Cache-Control, while plain names must be
+// revalidated with their ETag.
+//
+// If a file has a precompressed variant next to it, e.g. app.js.br or
+// app.js.gz, the variant is served to clients that accept its encoding.
+type AssetServer struct {
+	fsys   fs.FS
+	prefix string
+	assets map[string]*asset
+	hashed map[string]*asset
+}
+
+// NewAssetServer creates an asset server that serves the files of fsys under
+// the URL path prefix, e.g. /static/.
+//
+// An error is returned if any file of fsys cannot be read.
+func NewAssetServer(fsys fs.FS, prefix string) (*AssetServer, error) {
+	s := &AssetServer{
+		fsys:   fsys,
+		prefix: "/" + strings.Trim(prefix, "/") + "/",
+		assets: make(map[string]*asset),
+		hashed: make(map[string]*asset),
+	}
+	if s.prefix == "//" {
+		s.prefix = "/"
+	}
+
+	files := make(map[string]struct{})
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry,
+		err error) error {
+		if err == nil && !d.IsDir() {
+			files[name] = struct{}{}
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	for name := range files {
+		if isEncodedVariant(name, files) {
+			continue
+		}
+
+		a, err := newAsset(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range assetEncodings {
+			if _, has := files[name+e.extension]; has {
+				a.encoded[e.encoding] = name + e.extension
+			}
+		}
+		s.assets[a.name] = a
+		s.hashed[a.hashedName] = a
+	}
+	return s, nil
+}
+
+// Path returns the fingerprinted URL path of the named asset. If the asset
+// does not exist, the plain URL path is returned.
+func (s *AssetServer) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if a, has := s.assets[name]; has {
+		return s.prefix + a.hashedName
+	}
+	return s.prefix + name
+}
+
+// TemplateFuncs returns the template functions of the asset server, which can
+// be passed to RegisterTemplateFuncs or template.Template.Funcs. Templates
+// loaded by this package already have an asset function that uses the server
+// registered with RegisterAssetServer.
+//
+// The following functions are provided:
+//   - asset: returns the fingerprinted URL path of an asset.
+func (s *AssetServer) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset": s.Path,
+	}
+}
+
+// ServeHTTP serves the asset the request refers to.
+func (s *AssetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, found := strings.CutPrefix(r.URL.Path, s.prefix)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	a, immutable := s.hashed[name]
+	if immutable {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else if a, found = s.assets[name]; found {
+		header.Set("Cache-Control", "no-cache")
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, etag := a.name, `"`+a.hash+`"`
+	if len(a.encoded) > 0 {
+		addVary(header, "Accept-Encoding")
+		available := make([]string, 0, len(a.encoded))
+		for _, e := range assetEncodings {
+			if _, has := a.encoded[e.encoding]; has {
+				available = append(available, e.encoding)
+			}
+		}
+		if encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"),
+			available...); encoding != "" {
+			file, etag = a.encoded[encoding], `"`+a.hash+"-"+encoding+`"`
+			header.Set("Content-Encoding", encoding)
+		}
+	}
+	header.Set("ETag", etag)
+
+	content, err := openSeeker(s.fsys, file)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+	// The original name is passed, so that the content type is determined by
+	// the extension of the asset rather than of the precompressed variant.
+	http.ServeContent(w, r, a.name, a.modTime, content)
+}
+
+// newAsset hashes the named file.
+func newAsset(fsys fs.FS, name string) (*asset, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	ext := path.Ext(name)
+	return &asset{
+		name:       name,
+		hashedName: strings.TrimSuffix(name, ext) + "." + hash[:16] + ext,
+		hash:       hash,
+		modTime:    info.ModTime(),
+		encoded:    make(map[string]string),
+	}, nil
+}
+
+// isEncodedVariant reports whether name is a precompressed variant of another
+// file.
+func isEncodedVariant(name string, files map[string]struct{}) bool {
+	for _, e := range assetEncodings {
+		if original, found := strings.CutSuffix(name, e.extension); found {
+			if _, has := files[original]; has {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// openSeeker opens the named file as an io.ReadSeeker, reading it into memory
+// if the file system does not support seeking.
+func openSeeker(fsys fs.FS, name string) (io.ReadSeeker, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
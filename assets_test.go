@@ -0,0 +1,174 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestAssetServer(t *testing.T) *AssetServer {
+	t.Helper()
+
+	s, err := NewAssetServer(fstest.MapFS{
+		"app.js":         {Data: []byte("console.log('app');")},
+		"app.js.br":      {Data: []byte("brotli")},
+		"app.js.gz":      {Data: []byte("gzip")},
+		"css/site.css":   {Data: []byte("body{}")},
+		"archive.tar.gz": {Data: []byte("archive")},
+	}, "/static/")
+	if err != nil {
+		t.Fatalf("Unable to create asset server: %v", err)
+	}
+	return s
+}
+
+func TestAssetServerPath(t *testing.T) {
+	s := newTestAssetServer(t)
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"app.js", "/static/app.????????????????.js"},
+		{"/css/site.css", "/static/css/site.????????????????.css"},
+		{"archive.tar.gz", "/static/archive.tar.????????????????.gz"},
+		{"missing.js", "/static/missing.js"},
+	}
+
+	for _, test := range tests {
+		got := s.Path(test.name)
+		if len(got) != len(test.pattern) {
+			t.Errorf("Path(%q) should match %q, got: %q", test.name,
+				test.pattern, got)
+			continue
+		}
+		for i := range got {
+			if test.pattern[i] != '?' && test.pattern[i] != got[i] {
+				t.Errorf("Path(%q) should match %q, got: %q", test.name,
+					test.pattern, got)
+				break
+			}
+		}
+	}
+
+	if _, has := s.assets["app.js.br"]; has {
+		t.Error("Precompressed variants should not be assets of their own")
+	}
+}
+
+func TestAssetServer(t *testing.T) {
+	s := newTestAssetServer(t)
+	hashed := s.Path("app.js")
+
+	serve := func(method string, target string,
+		header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		for name, value := range header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", hashed, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "console.log('app');" {
+		t.Errorf("Fingerprinted asset should be served, got: %d %q", w.Code,
+			w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); !strings.Contains(got,
+		"immutable") {
+		t.Errorf("Fingerprinted assets should be immutable, got: %q", got)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.Contains(got,
+		"javascript") {
+		t.Errorf("Expected a JavaScript content type, got: %q", got)
+	}
+	etag := w.Header().Get("ETag")
+
+	w = serve("GET", "/static/app.js", nil)
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Plain assets should be revalidated, got: %q", got)
+	}
+
+	w = serve("GET", hashed, map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Matching ETag should result in 304, got: %d", w.Code)
+	}
+
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"gzip, deflate, br", "br", "brotli"},
+		{"gzip", "gzip", "gzip"},
+		{"br;q=0.5, gzip", "gzip", "gzip"},
+		{"br;q=0, *", "gzip", "gzip"},
+		{"identity", "", "console.log('app');"},
+	}
+	for _, test := range tests {
+		w = serve("GET", hashed,
+			map[string]string{"Accept-Encoding": test.acceptEncoding})
+		if got := w.Header().Get("Content-Encoding"); got != test.encoding {
+			t.Errorf("Accept-Encoding %q: expected encoding %q, got: %q",
+				test.acceptEncoding, test.encoding, got)
+		}
+		if w.Body.String() != test.body {
+			t.Errorf("Accept-Encoding %q: expected body %q, got: %q",
+				test.acceptEncoding, test.body, w.Body.String())
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: Vary should be set",
+				test.acceptEncoding)
+		}
+		if test.encoding != "" && w.Header().Get("ETag") == etag {
+			t.Errorf("Accept-Encoding %q: encoded variants should have a "+
+				"different ETag", test.acceptEncoding)
+		}
+	}
+
+	if w = serve("GET", "/static/missing.js", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Missing assets should result in 404, got: %d", w.Code)
+	}
+	if w = serve("POST", hashed, nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST should result in 405, got: %d", w.Code)
+	}
+}
+
+func TestAssetTemplateFunc(t *testing.T) {
+	s := newTestAssetServer(t)
+	tmpl, err := NewTemplateLoader(fstest.MapFS{
+		"page.html": {Data: []byte(`<script src="{{asset "app.js"}}"></script>`)},
+	}).Load("page.html", false)
+	if err != nil {
+		t.Fatalf("Template using asset should parse: %v", err)
+	}
+
+	RegisterAssetServer(s)
+	defer defaultAssetServer.Store(nil)
+
+	var b strings.Builder
+	if err = tmpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<script src="` + s.Path("app.js") + `"></script>`; b.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, b.String())
+	}
+}
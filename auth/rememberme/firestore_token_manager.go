@@ -40,6 +40,12 @@ func NewFirestoreTokenManager(client *firestore.Client,
 	}
 }
 
+// Check checks that the token collection can be read, so that the manager can
+// be used as a readiness check, e.g. with webapp.Server.AddReadinessCheck.
+func (m *FirestoreTokenManager) Check(ctx context.Context) error {
+	return f.Ping(ctx, m.client, m.collectionName)
+}
+
 // Add adds the token to the underlying datastore
 //
 // This function will return ErrTokenDuplicate if the given Username
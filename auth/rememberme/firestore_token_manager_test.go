@@ -17,6 +17,7 @@ package rememberme_test
 import (
 	"context"
 	"log"
+	"testing"
 
 	"cloud.google.com/go/firestore"
 	"github.com/qqiao/webapp/v2/auth/rememberme"
@@ -32,3 +33,10 @@ func init() {
 
 	managers["FirestoreTokenManager"] = tm
 }
+
+func TestFirestoreTokenManagerCheck(t *testing.T) {
+	m := managers["FirestoreTokenManager"].(*rememberme.FirestoreTokenManager)
+	if err := m.Check(context.Background()); err != nil {
+		t.Errorf("Check should succeed: %v", err)
+	}
+}
@@ -41,6 +41,12 @@ func NewFirestoreManager(client *firestore.Client,
 	}
 }
 
+// Check checks that the user collection can be read, so that the manager can
+// be used as a readiness check, e.g. with webapp.Server.AddReadinessCheck.
+func (m *FirestoreManager) Check(ctx context.Context) error {
+	return f.Ping(ctx, m.client, m.collectionName)
+}
+
 // Add adds a user to the database of users.
 //
 // Please note that a user is considered a duplicate if any of the following
@@ -17,6 +17,7 @@ package user_test
 import (
 	"context"
 	"log"
+	"testing"
 
 	"cloud.google.com/go/firestore"
 	"github.com/qqiao/webapp/v2/auth/user"
@@ -32,3 +33,10 @@ func init() {
 
 	managers["FirestoreManager"] = m
 }
+
+func TestFirestoreManagerCheck(t *testing.T) {
+	m := managers["FirestoreManager"].(*user.FirestoreManager)
+	if err := m.Check(context.Background()); err != nil {
+		t.Errorf("Check should succeed: %v", err)
+	}
+}
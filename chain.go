@@ -0,0 +1,99 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import "net/http"
+
+// Middleware wraps an HTTP handler to add behaviour before and/or after it.
+type Middleware func(h http.Handler) http.Handler
+
+// NewMiddleware turns a handler constructor that takes options, such as
+// NewHSTSHandler or NewLocaleHandler, into a Middleware with the given
+// options, e.g.
+//
+//	NewMiddleware(NewHSTSHandler, NewHSTSOptions())
+func NewMiddleware[O any](constructor func(http.Handler, O) http.Handler,
+	opts O) Middleware {
+	return func(h http.Handler) http.Handler {
+		return constructor(h, opts)
+	}
+}
+
+// HandlerFuncMiddleware adapts middleware working with http.HandlerFunc, such
+// as HSTSHandler, into a Middleware.
+func HandlerFuncMiddleware(m func(http.HandlerFunc) http.HandlerFunc) Middleware {
+	return func(h http.Handler) http.Handler {
+		return m(h.ServeHTTP)
+	}
+}
+
+// Chain is an ordered list of middleware.
+//
+// Middleware are applied in the order they are added, so the first one is
+// the outermost and sees the request first:
+//
+//	NewChain(logging, recovery).Then(h)
+//
+// is the same as logging(recovery(h)).
+//
+// A Chain is immutable, Append and Extend return new chains, so a common base
+// chain can be shared by routes that add their own middleware:
+//
+//	base := NewChain(logging, recovery, hsts)
+//	mux.Handle("/", base.Then(home))
+//	mux.Handle("/account", base.Append(session, csrf).Then(account))
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain creates a chain of the given middleware.
+func NewChain(middlewares ...Middleware) Chain {
+	return Chain{middlewares: append([]Middleware(nil), middlewares...)}
+}
+
+// Append returns a new chain with the given middleware added after the
+// middleware of c.
+func (c Chain) Append(middlewares ...Middleware) Chain {
+	combined := make([]Middleware, 0, len(c.middlewares)+len(middlewares))
+	combined = append(combined, c.middlewares...)
+	return Chain{middlewares: append(combined, middlewares...)}
+}
+
+// Extend returns a new chain with the middleware of other added after the
+// middleware of c.
+func (c Chain) Extend(other Chain) Chain {
+	return c.Append(other.middlewares...)
+}
+
+// Then wraps h with all the middleware of the chain and returns the result.
+// If h is nil, http.DefaultServeMux is used.
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// ThenFunc works the same way as Then, except that it takes an
+// http.HandlerFunc.
+func (c Chain) ThenFunc(f http.HandlerFunc) http.Handler {
+	if f == nil {
+		return c.Then(nil)
+	}
+	return c.Then(f)
+}
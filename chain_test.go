@@ -0,0 +1,83 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tagMiddleware appends tag to the X-Order header before calling the next
+// handler.
+func tagMiddleware(tag string) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Order", tag)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func order(h http.Handler) string {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	return strings.Join(w.Header().Values("X-Order"), ",")
+}
+
+func TestChain(t *testing.T) {
+	base := NewChain(tagMiddleware("a"), tagMiddleware("b"))
+	withC := base.Append(tagMiddleware("c"))
+	withD := base.Append(tagMiddleware("d"))
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		expected string
+	}{
+		{"Empty chain", NewChain().Then(okHandler), ""},
+		{"Base chain", base.Then(okHandler), "a,b"},
+		{"Appended chain", withC.Then(okHandler), "a,b,c"},
+		{"Sibling chain", withD.ThenFunc(okHandler), "a,b,d"},
+		{"Extended chain", withC.Extend(NewChain(tagMiddleware("e"))).
+			Then(okHandler), "a,b,c,e"},
+	}
+
+	for _, test := range tests {
+		if got := order(test.handler); got != test.expected {
+			t.Errorf("%s: expected order %q, got: %q", test.name,
+				test.expected, got)
+		}
+	}
+}
+
+func TestChainAdapters(t *testing.T) {
+	h := NewChain(
+		HandlerFuncMiddleware(HSTSHandler),
+		NewMiddleware(NewLocaleHandler, NewLocaleOptions("en", "fr")),
+	).Then(okHandler)
+
+	r := httptest.NewRequest("GET", "/fr/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Header().Get("Strict-Transport-Security") == "" {
+		t.Error("HandlerFunc middleware should be applied")
+	}
+	if got := w.Header().Get("Content-Language"); got != "fr" {
+		t.Errorf("Middleware with options should be applied, got: %q", got)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultCompressionMinSize is the minimum size of the responses compressed
+// by default, smaller responses are not worth the overhead.
+const DefaultCompressionMinSize = 1024
+
+// compressionEncodings are the supported content codings, in order of
+// preference.
+var compressionEncodings = []string{"br", "gzip"}
+
+var (
+	gzipWriterPool = sync.Pool{New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}}
+	brotliWriterPool = sync.Pool{New: func() any {
+		return brotli.NewWriter(io.Discard)
+	}}
+)
+
+// compressor is implemented by both *gzip.Writer and *brotli.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// CompressionOptions controls which responses are compressed by the handler
+// created with NewCompressionHandler.
+type CompressionOptions struct {
+	minSize      int
+	contentTypes []string
+}
+
+// NewCompressionOptions creates compression options that compress text,
+// JSON, JavaScript, XML and SVG responses of at least
+// DefaultCompressionMinSize bytes.
+func NewCompressionOptions() *CompressionOptions {
+	return &CompressionOptions{
+		minSize: DefaultCompressionMinSize,
+		contentTypes: []string{
+			"text/",
+			"application/javascript",
+			"application/json",
+			"application/manifest+json",
+			"application/problem+json",
+			"application/wasm",
+			"application/xml",
+			"image/svg+xml",
+		},
+	}
+}
+
+// WithMinSize sets the minimum size of the responses that are compressed.
+func (o *CompressionOptions) WithMinSize(size int) *CompressionOptions {
+	o.minSize = size
+	return o
+}
+
+// WithContentTypes sets the media types of the responses that are
+// compressed. A type ending with a slash, e.g. text/, matches all of its
+// subtypes.
+func (o *CompressionOptions) WithContentTypes(types ...string) *CompressionOptions {
+	o.contentTypes = types
+	return o
+}
+
+// compressible reports whether responses with the given content type should
+// be compressed.
+func (o *CompressionOptions) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range o.contentTypes {
+		if strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) ||
+			mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCompressionHandler takes a normal HTTP handler and compresses its
+// responses with brotli or gzip, whichever the client prefers according to
+// the Accept-Encoding header.
+//
+// Only responses with a compressible content type and at least the minimum
+// size are compressed. Responses that already have a Content-Encoding, partial
+// content and responses to HEAD and Range requests are passed through as is.
+// The Content-Length of compressed responses is removed, and strong ETags are
+// made weak, since the compressed representation differs from the original.
+func NewCompressionHandler(h http.Handler, opts *CompressionOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addVary(w.Header(), "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"),
+			compressionEncodings...)
+		if encoding == "" || r.Method == http.MethodHead ||
+			r.Header.Get("Range") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, opts: opts, encoding: encoding}
+		h.ServeHTTP(cw, r)
+		// The writer is deliberately not closed if h panics, so that buffered
+		// content does not turn the failure into a successful response.
+		cw.close()
+	})
+}
+
+// compressWriter buffers the start of a response until it can decide whether
+// to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *CompressionOptions
+	encoding string
+
+	status      int
+	buf         []byte
+	decided     bool
+	compressor  compressor
+	wroteHeader bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		// Informational responses are sent as is.
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.decided {
+		return w.write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.opts.minSize {
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush implements http.Flusher. Flushing before the minimum size is reached
+// decides on compression with what has been written so far.
+func (w *compressWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided && w.decide(true) != nil {
+		return
+	}
+	if w.compressor != nil {
+		w.compressor.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, so that websockets keep working.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.decided = true
+		w.wroteHeader = true
+		return hj.Hijack()
+	}
+	return nil, nil, errors.ErrUnsupported
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by
+// http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide decides whether to compress the response, writes the header and the
+// buffered content. If flushing is false, the response is only compressed if
+// the buffered content reaches the minimum size.
+func (w *compressWriter) decide(flushing bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if w.eligible(flushing) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag,
+			"W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		if w.encoding == "br" {
+			w.compressor = brotliWriterPool.Get().(*brotli.Writer)
+		} else {
+			w.compressor = gzipWriterPool.Get().(*gzip.Writer)
+		}
+		w.compressor.Reset(w.ResponseWriter)
+	}
+
+	w.writeHeader()
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+// eligible reports whether the response should be compressed.
+func (w *compressWriter) eligible(flushing bool) bool {
+	header := w.Header()
+	switch {
+	case w.status < 200, w.status == http.StatusNoContent,
+		w.status == http.StatusNotModified,
+		w.status == http.StatusPartialContent:
+		return false
+	case header.Get("Content-Encoding") != "",
+		header.Get("Content-Range") != "":
+		return false
+	case !w.opts.compressible(header.Get("Content-Type")):
+		return false
+	}
+
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil &&
+		length < w.opts.minSize {
+		return false
+	}
+	return flushing || len(w.buf) >= w.opts.minSize
+}
+
+func (w *compressWriter) writeHeader() {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.compressor != nil {
+		return w.compressor.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// close writes out any buffered content, finishes the compressed stream and
+// returns the compressor to its pool.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			// Nothing has been written, let the server send its default
+			// response.
+			return
+		}
+		w.decide(false)
+	}
+	if w.compressor == nil {
+		return
+	}
+
+	w.compressor.Close()
+	w.compressor.Reset(io.Discard)
+	switch c := w.compressor.(type) {
+	case *brotli.Writer:
+		brotliWriterPool.Put(c)
+	case *gzip.Writer:
+		gzipWriterPool.Put(c)
+	}
+	w.compressor = nil
+}
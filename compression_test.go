@@ -0,0 +1,230 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+var compressibleText = strings.Repeat("Hello, world! ", 200)
+
+// contentHandler writes body with the given content type and extra headers.
+func contentHandler(contentType string, body string,
+	header map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		for name, value := range header {
+			w.Header().Set(name, value)
+		}
+		io.WriteString(w, body)
+	})
+}
+
+// decode decodes the body of the response according to its Content-Encoding.
+func decode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var r io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case "gzip":
+		gr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Invalid gzip stream: %v", err)
+		}
+		r = gr
+	case "br":
+		r = brotli.NewReader(w.Body)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Unable to decode body: %v", err)
+	}
+	return string(b)
+}
+
+func TestCompressionHandler(t *testing.T) {
+	opts := NewCompressionOptions()
+
+	tests := []struct {
+		name             string
+		handler          http.Handler
+		method           string
+		header           map[string]string
+		expectedEncoding string
+	}{
+		{"Brotli should be preferred",
+			contentHandler("text/html", compressibleText, nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip, br"}, "br"},
+		{"Gzip should be used if preferred",
+			contentHandler("text/html", compressibleText, nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip, br;q=0.5"}, "gzip"},
+		{"Content type should be sniffed",
+			contentHandler("", compressibleText, nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip"}, "gzip"},
+		{"Small responses should not be compressed",
+			contentHandler("text/html", "Hello", nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip"}, ""},
+		{"Incompressible types should not be compressed",
+			contentHandler("image/png", compressibleText, nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip"}, ""},
+		{"Encoded responses should not be compressed again",
+			contentHandler("text/html", compressibleText,
+				map[string]string{"Content-Encoding": "identity"}), "GET",
+			map[string]string{"Accept-Encoding": "gzip"}, "identity"},
+		{"Range requests should not be compressed",
+			contentHandler("text/html", compressibleText, nil), "GET",
+			map[string]string{"Accept-Encoding": "gzip",
+				"Range": "bytes=0-10"}, ""},
+		{"HEAD requests should not be compressed",
+			contentHandler("text/html", compressibleText, nil), "HEAD",
+			map[string]string{"Accept-Encoding": "gzip"}, ""},
+		{"Clients without Accept-Encoding should not get compression",
+			contentHandler("text/html", compressibleText, nil), "GET",
+			nil, ""},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/", nil)
+		for name, value := range test.header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		NewCompressionHandler(test.handler, opts).ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != test.expectedEncoding {
+			t.Errorf("%s: expected encoding %q, got: %q", test.name,
+				test.expectedEncoding, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s: expected Vary: Accept-Encoding, got: %q", test.name,
+				got)
+		}
+		if test.method == "GET" && test.expectedEncoding != "identity" {
+			body := decode(t, w)
+			expected := compressibleText
+			if strings.Contains(test.name, "Small") {
+				expected = "Hello"
+			}
+			if body != expected {
+				t.Errorf("%s: body should survive compression", test.name)
+			}
+		}
+	}
+}
+
+func TestCompressionHandlerHeaders(t *testing.T) {
+	h := NewCompressionHandler(contentHandler("application/json",
+		compressibleText, map[string]string{
+			"Content-Length": "2800",
+			"ETag":           `"abc"`,
+		}), NewCompressionOptions())
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length should be removed, got: %q", got)
+	}
+	if got := w.Header().Get("ETag"); got != `W/"abc"` {
+		t.Errorf("ETag should be made weak, got: %q", got)
+	}
+	if w.Body.Len() >= len(compressibleText) {
+		t.Errorf("Body should be compressed, got %d bytes", w.Body.Len())
+	}
+}
+
+func TestCompressionHandlerStatus(t *testing.T) {
+	h := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, compressibleText)
+	}), NewCompressionOptions())
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "br")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Status should be kept, got: %d", w.Code)
+	}
+	if decode(t, w) != compressibleText {
+		t.Error("Error responses should be compressed as well")
+	}
+}
+
+func TestCompressionHandlerFlush(t *testing.T) {
+	h := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: 1\n\n")
+		w.(http.Flusher).Flush()
+		if !w.(*compressWriter).decided {
+			t.Error("Flushing should write out the response")
+		}
+		io.WriteString(w, "data: 2\n\n")
+	}), NewCompressionOptions())
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if !w.Flushed {
+		t.Error("Flush should be passed on")
+	}
+	if got := decode(t, w); got != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("Unexpected streamed body: %q", got)
+	}
+}
+
+func TestCompressionHandlerComposition(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewChain(
+		HandlerFuncMiddleware(HSTSHandler),
+		NewMiddleware(NewLoggingHandler,
+			NewLoggingOptions().WithLogger(newTestLogger(&buf))),
+		NewMiddleware(NewCompressionHandler, NewCompressionOptions()),
+	).Then(contentHandler("text/html", compressibleText, nil))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Header().Get("Strict-Transport-Security") == "" {
+		t.Error("HSTS header should be sent")
+	}
+	if record := lastRecord(t, &buf); record["bytes"] != float64(w.Body.Len()) {
+		t.Errorf("Compressed size should be logged, got: %v",
+			record["bytes"])
+	}
+	if decode(t, w) != compressibleText {
+		t.Error("Body should survive compression")
+	}
+}
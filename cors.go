@@ -0,0 +1,251 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsOrigin is an allowed origin, either exact or with a wildcard in place
+// of the subdomain, e.g. https://*.example.com.
+type corsOrigin struct {
+	prefix   string
+	suffix   string
+	wildcard bool
+}
+
+func newCORSOrigin(origin string) corsOrigin {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	if before, after, found := strings.Cut(origin, "*"); found {
+		return corsOrigin{prefix: before, suffix: after, wildcard: true}
+	}
+	return corsOrigin{prefix: origin}
+}
+
+// matches reports whether the origin matches. Wildcards match one or more
+// subdomain labels, but not the bare domain.
+func (o corsOrigin) matches(origin string) bool {
+	if !o.wildcard {
+		return origin == o.prefix
+	}
+	if len(origin) <= len(o.prefix)+len(o.suffix) ||
+		!strings.HasPrefix(origin, o.prefix) ||
+		!strings.HasSuffix(origin, o.suffix) {
+		return false
+	}
+	subdomain := origin[len(o.prefix) : len(origin)-len(o.suffix)]
+	return !strings.ContainsAny(subdomain, "/:@?#")
+}
+
+// CORSOptions controls which cross-origin requests are allowed by the
+// handler created with NewCORSHandler.
+type CORSOptions struct {
+	allowAll       bool
+	origins        []corsOrigin
+	credentials    bool
+	methods        []string
+	headers        []string
+	exposedHeaders []string
+	maxAge         time.Duration
+}
+
+// NewCORSOptions creates CORS options that allow requests from the given
+// origins. Origins are either exact, e.g. https://app.example.com, contain a
+// wildcard in place of the subdomain, e.g. https://*.example.com, or are a
+// single * to allow any origin.
+//
+// By default, the GET, HEAD, POST, PUT, PATCH and DELETE methods and the
+// Authorization, Content-Type and X-Requested-With headers are allowed, and
+// preflight responses are cached for 10 minutes.
+func NewCORSOptions(origins ...string) *CORSOptions {
+	o := &CORSOptions{
+		methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		headers:        []string{"Authorization", "Content-Type", "X-Requested-With"},
+		exposedHeaders: []string{},
+		maxAge:         10 * time.Minute,
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			o.allowAll = true
+		} else {
+			o.origins = append(o.origins, newCORSOrigin(origin))
+		}
+	}
+	return o
+}
+
+// WithCredentials sets whether credentials such as cookies and the
+// Authorization header may be sent with cross-origin requests.
+//
+// Browsers do not send credentials to servers allowing any origin with *, so
+// credentials are only allowed for origins that are listed explicitly.
+func (o *CORSOptions) WithCredentials(credentials bool) *CORSOptions {
+	o.credentials = credentials
+	return o
+}
+
+// WithMethods sets the methods cross-origin requests may use.
+func (o *CORSOptions) WithMethods(methods ...string) *CORSOptions {
+	o.methods = methods
+	return o
+}
+
+// WithHeaders sets the request headers cross-origin requests may send, in
+// addition to the CORS-safelisted headers.
+func (o *CORSOptions) WithHeaders(headers ...string) *CORSOptions {
+	o.headers = headers
+	return o
+}
+
+// WithExposedHeaders sets the response headers, other than the
+// CORS-safelisted ones, that scripts are allowed to read.
+func (o *CORSOptions) WithExposedHeaders(headers ...string) *CORSOptions {
+	o.exposedHeaders = headers
+	return o
+}
+
+// WithMaxAge sets how long browsers may cache preflight responses. A zero
+// max age disables the Access-Control-Max-Age header.
+func (o *CORSOptions) WithMaxAge(maxAge time.Duration) *CORSOptions {
+	o.maxAge = maxAge
+	return o
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin for the
+// origin, or an empty string if the origin is not allowed, along with whether
+// credentials are allowed.
+func (o *CORSOptions) allowOrigin(origin string) (string, bool) {
+	lower := strings.ToLower(origin)
+	for _, allowed := range o.origins {
+		if allowed.matches(lower) {
+			return origin, o.credentials
+		}
+	}
+	if o.allowAll {
+		return "*", false
+	}
+	return "", false
+}
+
+func (o *CORSOptions) allowMethod(method string) bool {
+	for _, m := range o.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *CORSOptions) allowHeader(header string) bool {
+	switch strings.ToLower(header) {
+	case "accept", "accept-language", "content-language", "content-type":
+		return true
+	}
+	for _, h := range o.headers {
+		if strings.EqualFold(h, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCORSHandler takes a normal HTTP handler and applies the CORS policy of
+// opts to it.
+//
+// Preflight requests are answered directly with 204 No Content and never
+// reach h, so that authentication handlers wrapped by this one do not reject
+// them. Requests from origins that are not allowed are passed on without any
+// CORS headers, and it is up to the browser to block them.
+func NewCORSHandler(h http.Handler, opts *CORSOptions) http.Handler {
+	methods := strings.Join(opts.methods, ", ")
+	exposed := strings.Join(opts.exposedHeaders, ", ")
+	maxAge := ""
+	if opts.maxAge > 0 {
+		maxAge = strconv.Itoa(int(opts.maxAge / time.Second))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		addVary(header, "Origin")
+
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions &&
+			r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			addVary(header, "Access-Control-Request-Method")
+			addVary(header, "Access-Control-Request-Headers")
+		}
+
+		allowOrigin, credentials := "", false
+		if origin != "" {
+			allowOrigin, credentials = opts.allowOrigin(origin)
+		}
+
+		if !preflight {
+			if allowOrigin != "" {
+				header.Set("Access-Control-Allow-Origin", allowOrigin)
+				if credentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if exposed != "" {
+					header.Set("Access-Control-Expose-Headers", exposed)
+				}
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		defer w.WriteHeader(http.StatusNoContent)
+		if allowOrigin == "" ||
+			!opts.allowMethod(r.Header.Get("Access-Control-Request-Method")) {
+			return
+		}
+		requested := make([]string, 0)
+		for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name == "" {
+					continue
+				}
+				if !opts.allowHeader(name) {
+					return
+				}
+				requested = append(requested, name)
+			}
+		}
+
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+		if credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		header.Set("Access-Control-Allow-Methods", methods)
+		if len(requested) > 0 {
+			header.Set("Access-Control-Allow-Headers",
+				strings.Join(requested, ", "))
+		}
+		if maxAge != "" {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSOrigin(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		origin   string
+		expected bool
+	}{
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com/", "https://app.example.com", true},
+		{"https://app.example.com", "http://app.example.com", false},
+		{"https://*.example.com", "https://a.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://evil.com/.example.com", false},
+		{"https://*.example.com", "https://evilexample.com", false},
+		{"https://*.example.com:8443", "https://a.example.com:8443", true},
+		{"https://*.example.com:8443", "https://a.example.com", false},
+	}
+
+	for _, test := range tests {
+		if got := newCORSOrigin(test.pattern).matches(test.origin); got !=
+			test.expected {
+			t.Errorf("%q matching %q should be %t, got: %t", test.pattern,
+				test.origin, test.expected, got)
+		}
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	reached := false
+	h := NewCORSHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		reached = true
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}), NewCORSOptions("https://app.example.com", "https://*.example.org").
+		WithCredentials(true).
+		WithExposedHeaders("X-Request-ID").
+		WithMaxAge(time.Hour))
+
+	serve := func(method string, header map[string]string) *httptest.ResponseRecorder {
+		reached = false
+		r := httptest.NewRequest(method, "/api", nil)
+		for name, value := range header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("OPTIONS", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "authorization, content-type",
+	})
+	if reached {
+		t.Error("Preflight requests should not reach the handler")
+	}
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, HEAD, POST, PUT, PATCH, DELETE",
+		"Access-Control-Allow-Headers":     "authorization, content-type",
+		"Access-Control-Max-Age":           "3600",
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Preflight should result in 204, got: %d", w.Code)
+	}
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("Preflight: expected %s %q, got: %q", name, value, got)
+		}
+	}
+	if got := w.Header().Values("Vary"); len(got) != 3 {
+		t.Errorf("Preflight should vary on the request headers, got: %v", got)
+	}
+
+	rejected := []map[string]string{
+		{"Origin": "https://evil.example", "Access-Control-Request-Method": "GET"},
+		{"Origin": "https://app.example.com",
+			"Access-Control-Request-Method": "TRACE"},
+		{"Origin": "https://app.example.com",
+			"Access-Control-Request-Method":  "GET",
+			"Access-Control-Request-Headers": "X-Secret"},
+	}
+	for _, header := range rejected {
+		w = serve("OPTIONS", header)
+		if reached || w.Code != http.StatusNoContent {
+			t.Errorf("Rejected preflight %v should still be answered", header)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Rejected preflight %v should have no CORS headers, "+
+				"got: %q", header, got)
+		}
+	}
+
+	w = serve("GET", map[string]string{
+		"Origin":        "https://api.example.org",
+		"Authorization": "Bearer token",
+	})
+	if !reached || w.Code != http.StatusOK {
+		t.Errorf("Actual requests should reach the handler, got: %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got !=
+		"https://api.example.org" {
+		t.Errorf("Wildcard origins should be allowed, got: %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got !=
+		"X-Request-ID" {
+		t.Errorf("Exposed headers should be sent, got: %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Actual requests should vary on Origin, got: %q", got)
+	}
+
+	w = serve("GET", map[string]string{"Origin": "https://evil.example"})
+	if !reached || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Disallowed origins should be passed on without CORS headers")
+	}
+}
+
+func TestCORSHandlerAllowAll(t *testing.T) {
+	h := NewCORSHandler(okHandler, NewCORSOptions("*").WithCredentials(true))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "https://anywhere.example")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Any origin should be allowed, got: %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Credentials should not be allowed with *, got: %q", got)
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/qqiao/webapp/v2"
+	"github.com/qqiao/webapp/v2/internal/httpheader"
+	"github.com/qqiao/webapp/v2/internal/proxy"
+)
+
+// Errors.
+var (
+	ErrBadOrigin = errors.New("csrf: origin not allowed")
+	ErrNoToken   = errors.New("csrf: token missing")
+	ErrBadToken  = errors.New("csrf: token invalid")
+)
+
+// Default names used by Options.
+const (
+	DefaultCookieName = "_csrf"
+	DefaultFieldName  = "csrf_token"
+	DefaultHeaderName = "X-CSRF-Token"
+)
+
+// secretLength is the length of the secret kept in the cookie in bytes.
+const secretLength = 32
+
+type contextKey struct{}
+
+// state is the CSRF state of a request stored in its context.
+type state struct {
+	secret []byte
+	opts   *Options
+	err    error
+}
+
+func init() {
+	webapp.RegisterRequestTemplateFuncs(TemplateFuncs)
+	for _, err := range []error{ErrBadOrigin, ErrNoToken, ErrBadToken} {
+		webapp.RegisterErrorStatus(err, http.StatusForbidden)
+	}
+}
+
+// Options controls the behaviour of the handler created with NewHandler.
+type Options struct {
+	cookieName     string
+	fieldName      string
+	headerName     string
+	secure         bool
+	headerMode     bool
+	trustedOrigins []string
+	trustedProxies []netip.Prefix
+	errorHandler   http.Handler
+}
+
+// NewOptions creates CSRF options with the default cookie, field and header
+// names. The cookie is marked Secure by default.
+func NewOptions() *Options {
+	return &Options{
+		cookieName: DefaultCookieName,
+		fieldName:  DefaultFieldName,
+		headerName: DefaultHeaderName,
+		secure:     true,
+	}
+}
+
+// WithCookieName sets the name of the cookie the secret is kept in.
+func (o *Options) WithCookieName(name string) *Options {
+	o.cookieName = name
+	return o
+}
+
+// WithFieldName sets the name of the form field the token is read from.
+func (o *Options) WithFieldName(name string) *Options {
+	o.fieldName = name
+	return o
+}
+
+// WithHeaderName sets the name of the request header the token is read from,
+// and of the response header the token is sent in in header mode.
+func (o *Options) WithHeaderName(name string) *Options {
+	o.headerName = name
+	return o
+}
+
+// WithSecure sets whether the cookie is marked Secure. It should only be
+// disabled for development over plain HTTP.
+func (o *Options) WithSecure(secure bool) *Options {
+	o.secure = secure
+	return o
+}
+
+// WithHeaderMode sets whether the handler works in header mode, meant for
+// single page applications. In header mode, the token is sent in a response
+// header of every response, only the request header is checked, and
+// rejections are reported as JSON.
+func (o *Options) WithHeaderMode(headerMode bool) *Options {
+	o.headerMode = headerMode
+	return o
+}
+
+// WithTrustedOrigins sets the origins, other than the host of the request
+// itself, unsafe requests may come from, e.g. https://app.example.com.
+func (o *Options) WithTrustedOrigins(origins ...string) *Options {
+	o.trustedOrigins = origins
+	return o
+}
+
+// WithTrustedProxies sets the addresses of the reverse proxies whose
+// Forwarded and X-Forwarded-Proto headers are trusted to tell whether the
+// original request was made over HTTPS, and thus which scheme the origin of
+// the request must have.
+func (o *Options) WithTrustedProxies(prefixes ...netip.Prefix) *Options {
+	o.trustedProxies = prefixes
+	return o
+}
+
+// WithErrorHandler sets the handler rejected requests are passed to. The
+// reason of the rejection is available through FailureReason, and is reported
+// as 403 Forbidden by webapp.ErrorRenderer.
+//
+// By default, a 403 Forbidden response is sent with the reason as plain text,
+// or as JSON in header mode.
+func (o *Options) WithErrorHandler(h http.Handler) *Options {
+	o.errorHandler = h
+	return o
+}
+
+// NewHandler takes a normal HTTP handler and protects it against cross-site
+// request forgery.
+//
+// A new secret is issued in a cookie if the request does not have a valid one.
+// Requests with unsafe methods are only passed on to h if their origin is
+// allowed and they carry a valid token, they are passed to the error handler
+// otherwise.
+func NewHandler(h http.Handler, opts *Options) http.Handler {
+	errorHandler := opts.errorHandler
+	if errorHandler == nil {
+		errorHandler = http.HandlerFunc(defaultErrorHandler)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := &state{opts: opts}
+		if cookie, err := r.Cookie(opts.cookieName); err == nil {
+			s.secret = decodeSecret(cookie.Value)
+		}
+		issued := s.secret == nil
+		if issued {
+			s.secret = make([]byte, secretLength)
+			// crypto/rand.Read never returns an error.
+			rand.Read(s.secret)
+			http.SetCookie(w, &http.Cookie{
+				Name:     opts.cookieName,
+				Value:    base64.RawURLEncoding.EncodeToString(s.secret),
+				Path:     "/",
+				Secure:   opts.secure,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		httpheader.AddVary(w.Header(), "Cookie")
+		if opts.headerMode {
+			w.Header().Set(opts.headerName, mask(s.secret))
+		}
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, s))
+
+		if !safeMethod(r.Method) {
+			if !opts.allowedOrigin(r) {
+				s.err = ErrBadOrigin
+			} else if issued {
+				// A freshly issued secret cannot have a matching token.
+				s.err = ErrNoToken
+			} else {
+				s.err = opts.checkToken(r, s.secret)
+			}
+			if s.err != nil {
+				errorHandler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Token returns a masked token for the request, to be submitted with unsafe
+// requests. An empty string is returned if the request has not been through
+// the handler created with NewHandler.
+//
+// Every call returns a different token, all of which are valid for the
+// secret of the request.
+func Token(r *http.Request) string {
+	s, ok := r.Context().Value(contextKey{}).(*state)
+	if !ok {
+		return ""
+	}
+	return mask(s.secret)
+}
+
+// TemplateField returns a hidden input carrying the token of the request, to
+// be included in HTML forms.
+func TemplateField(r *http.Request) template.HTML {
+	s, ok := r.Context().Value(contextKey{}).(*state)
+	if !ok {
+		return ""
+	}
+	return template.HTML(`<input type="hidden" name="` +
+		template.HTMLEscapeString(s.opts.fieldName) + `" value="` +
+		mask(s.secret) + `">`)
+}
+
+// FailureReason returns the reason the request was rejected, or nil if the
+// request has not been rejected. It is meant to be called by the error
+// handler set with WithErrorHandler.
+func FailureReason(r *http.Request) error {
+	s, ok := r.Context().Value(contextKey{}).(*state)
+	if !ok {
+		return nil
+	}
+	return s.err
+}
+
+// TemplateFuncs returns the template functions of this package bound to the
+// request. They are registered with webapp.RegisterRequestTemplateFuncs when
+// the package is imported.
+//
+// The following functions are provided:
+//   - csrfField: renders a hidden input with the token.
+//   - csrfToken: returns the token.
+func TemplateFuncs(r *http.Request) template.FuncMap {
+	return template.FuncMap{
+		"csrfField": func() template.HTML { return TemplateField(r) },
+		"csrfToken": func() string { return Token(r) },
+	}
+}
+
+// allowedOrigin reports whether the origin of the request, as indicated by
+// the Origin or the Referer header, is the request itself or one of the
+// trusted origins. Requests without either header are allowed, since the
+// token check still applies to them.
+//
+// The origin of the request itself has its host and the scheme it was made
+// with, so that plain HTTP pages cannot submit requests to an HTTPS site.
+func (o *Options) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			return true
+		}
+		origin = referer
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := "http"
+	if proxy.IsSecureRequest(r, o.trustedProxies) {
+		scheme = "https"
+	}
+	if strings.EqualFold(u.Scheme, scheme) && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, trusted := range o.trustedOrigins {
+		if strings.EqualFold(u.Scheme+"://"+u.Host,
+			strings.TrimSuffix(trusted, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkToken checks the token submitted with the request against secret.
+func (o *Options) checkToken(r *http.Request, secret []byte) error {
+	token := r.Header.Get(o.headerName)
+	if token == "" && !o.headerMode {
+		token = r.PostFormValue(o.fieldName)
+	}
+	if token == "" {
+		return ErrNoToken
+	}
+
+	unmasked := unmask(token)
+	if unmasked == nil || subtle.ConstantTimeCompare(unmasked, secret) != 1 {
+		return ErrBadToken
+	}
+	return nil
+}
+
+// defaultErrorHandler rejects the request with 403 Forbidden.
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request) {
+	s := r.Context().Value(contextKey{}).(*state)
+	if !s.opts.headerMode {
+		http.Error(w, s.err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{"error": s.err.Error()})
+}
+
+// safeMethod reports whether method is safe as defined in RFC 9110.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// mask masks secret with a fresh one-time pad. The pad is prepended to the
+// masked secret.
+func mask(secret []byte) string {
+	token := make([]byte, 2*len(secret))
+	pad, masked := token[:len(secret)], token[len(secret):]
+	rand.Read(pad)
+	subtle.XORBytes(masked, pad, secret)
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// unmask recovers the secret from a masked token. nil is returned if the
+// token is malformed.
+func unmask(token string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) != 2*secretLength {
+		return nil
+	}
+	secret := make([]byte, secretLength)
+	subtle.XORBytes(secret, b[:secretLength], b[secretLength:])
+	return secret
+}
+
+// decodeSecret decodes the secret kept in the cookie. nil is returned if the
+// value is malformed.
+func decodeSecret(value string) []byte {
+	secret, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(secret) != secretLength {
+		return nil
+	}
+	return secret
+}
@@ -0,0 +1,297 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csrf
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/qqiao/webapp/v2"
+)
+
+// tokenHandler writes the token of the request as the response body.
+var tokenHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(Token(r)))
+})
+
+// issue performs a GET request through h and returns the issued cookie along
+// with the token written by tokenHandler.
+func issue(t *testing.T, h http.Handler) (*http.Cookie, string) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected a single cookie, got: %v", cookies)
+	}
+	return cookies[0], w.Body.String()
+}
+
+func TestHandlerIssuesCookie(t *testing.T) {
+	h := NewHandler(tokenHandler, NewOptions())
+	cookie, token := issue(t, h)
+
+	if cookie.Name != DefaultCookieName || !cookie.HttpOnly || !cookie.Secure ||
+		cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Unexpected cookie: %v", cookie)
+	}
+	if token == "" {
+		t.Error("Token should be available to the handler")
+	}
+
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("A valid cookie should not be issued again")
+	}
+	if w.Body.String() == token {
+		t.Error("Tokens should be masked differently every time")
+	}
+}
+
+func TestHandlerVary(t *testing.T) {
+	h := NewHandler(tokenHandler, NewOptions())
+	stacked := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Accept-Encoding, Cookie")
+		h.ServeHTTP(w, r)
+	})
+
+	w := httptest.NewRecorder()
+	stacked.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/",
+		nil))
+	if got := w.Header().Values("Vary"); len(got) != 1 {
+		t.Errorf("Cookie should not be added to Vary again, got: %q", got)
+	}
+}
+
+func TestHandlerChecksRequests(t *testing.T) {
+	var reason error
+	opts := NewOptions().
+		WithTrustedOrigins("https://app.example.com").
+		WithErrorHandler(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			reason = FailureReason(r)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+	h := NewHandler(tokenHandler, opts)
+	cookie, token := issue(t, h)
+	_, otherToken := issue(t, h)
+
+	tests := []struct {
+		name     string
+		cookie   *http.Cookie
+		header   map[string]string
+		form     url.Values
+		expected error
+	}{
+		{"Form token should be accepted", cookie, nil,
+			url.Values{DefaultFieldName: {token}}, nil},
+		{"Header token should be accepted", cookie,
+			map[string]string{DefaultHeaderName: token}, nil, nil},
+		{"Same origin should be accepted", cookie,
+			map[string]string{"Origin": "https://example.com",
+				DefaultHeaderName: token}, nil, nil},
+		{"Trusted origins should be accepted", cookie,
+			map[string]string{"Origin": "https://app.example.com",
+				DefaultHeaderName: token}, nil, nil},
+		{"Same origin referers should be accepted", cookie,
+			map[string]string{"Referer": "https://example.com/form",
+				DefaultHeaderName: token}, nil, nil},
+		{"Cross origin requests should be rejected", cookie,
+			map[string]string{"Origin": "https://evil.example",
+				DefaultHeaderName: token}, nil, ErrBadOrigin},
+		{"Cross origin referers should be rejected", cookie,
+			map[string]string{"Referer": "https://evil.example/form",
+				DefaultHeaderName: token}, nil, ErrBadOrigin},
+		{"Plain HTTP origins should be rejected", cookie,
+			map[string]string{"Origin": "http://example.com",
+				DefaultHeaderName: token}, nil, ErrBadOrigin},
+		{"Null origins should be rejected", cookie,
+			map[string]string{"Origin": "null", DefaultHeaderName: token}, nil,
+			ErrBadOrigin},
+		{"Missing tokens should be rejected", cookie, nil, nil, ErrNoToken},
+		{"Missing cookies should be rejected", nil,
+			map[string]string{DefaultHeaderName: token}, nil, ErrNoToken},
+		{"Tokens of other secrets should be rejected", cookie,
+			map[string]string{DefaultHeaderName: otherToken}, nil, ErrBadToken},
+		{"Malformed tokens should be rejected", cookie,
+			map[string]string{DefaultHeaderName: "abc"}, nil, ErrBadToken},
+	}
+
+	for _, test := range tests {
+		reason = nil
+		r := httptest.NewRequest("POST", "https://example.com/",
+			strings.NewReader(test.form.Encode()))
+		if test.form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if test.cookie != nil {
+			r.AddCookie(test.cookie)
+		}
+		for name, value := range test.header {
+			r.Header.Set(name, value)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if !errors.Is(reason, test.expected) {
+			t.Errorf("%s: expected reason %v, got: %v", test.name,
+				test.expected, reason)
+		}
+		if test.expected == nil && w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got: %d", test.name, w.Code)
+		}
+	}
+}
+
+func TestHandlerTrustedProxies(t *testing.T) {
+	proxyAddr := netip.MustParsePrefix("192.0.2.1/32")
+
+	tests := []struct {
+		name     string
+		proxies  []netip.Prefix
+		origin   string
+		expected int
+	}{
+		{"HTTPS origins should be accepted through trusted proxies",
+			[]netip.Prefix{proxyAddr}, "https://example.com", http.StatusOK},
+		{"HTTP origins should be rejected through trusted proxies",
+			[]netip.Prefix{proxyAddr}, "http://example.com",
+			http.StatusForbidden},
+		{"HTTPS origins should be rejected through untrusted proxies", nil,
+			"https://example.com", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		h := NewHandler(tokenHandler,
+			NewOptions().WithTrustedProxies(test.proxies...))
+		cookie, token := issue(t, h)
+
+		// httptest requests come from 192.0.2.1.
+		r := httptest.NewRequest("POST", "http://example.com/", nil)
+		r.AddCookie(cookie)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		r.Header.Set("Origin", test.origin)
+		r.Header.Set(DefaultHeaderName, token)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("%s: expected status %d, got: %d", test.name,
+				test.expected, w.Code)
+		}
+	}
+}
+
+func TestHandlerHeaderMode(t *testing.T) {
+	h := NewHandler(tokenHandler, NewOptions().WithHeaderMode(true))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	cookie := w.Result().Cookies()[0]
+	token := w.Header().Get(DefaultHeaderName)
+	if token == "" {
+		t.Fatal("Token should be sent in the response header")
+	}
+
+	r := httptest.NewRequest("POST", "http://example.com/",
+		strings.NewReader(url.Values{DefaultFieldName: {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Form tokens should be rejected in header mode, got: %d",
+			w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Rejections should be JSON in header mode, got: %s", ct)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil ||
+		body["error"] != ErrNoToken.Error() {
+		t.Errorf("Unexpected rejection body: %v, %v", body, err)
+	}
+
+	r = httptest.NewRequest("POST", "http://example.com/", nil)
+	r.Header.Set(DefaultHeaderName, token)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Header tokens should be accepted, got: %d", w.Code)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	loader := webapp.NewTemplateLoader(fstest.MapFS{
+		"form.html": {Data: []byte(`<form>{{csrfField}}</form>`)},
+	})
+	tmpl, err := loader.Load("form.html", false)
+	if err != nil {
+		t.Fatalf("Template using csrfField should parse: %v", err)
+	}
+
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if err := webapp.ExecuteTemplate(w, r, tmpl, nil); err != nil {
+			t.Error(err)
+		}
+	}), NewOptions().WithFieldName("token"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	body := w.Body.String()
+	if !strings.HasPrefix(body, `<form><input type="hidden" name="token" value="`) {
+		t.Errorf("Unexpected form: %s", body)
+	}
+
+	value := strings.TrimSuffix(strings.TrimPrefix(body,
+		`<form><input type="hidden" name="token" value="`), `"></form>`)
+	r := httptest.NewRequest("POST", "http://example.com/",
+		strings.NewReader(url.Values{"token": {value}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(w.Result().Cookies()[0])
+	w = httptest.NewRecorder()
+	NewHandler(tokenHandler, NewOptions().WithFieldName("token")).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Rendered token should be accepted, got: %d", w.Code)
+	}
+}
+
+func TestTokenWithoutHandler(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	if Token(r) != "" || TemplateField(r) != "" || FailureReason(r) != nil {
+		t.Error("Requests outside the handler should have no CSRF state")
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	for _, err := range []error{ErrBadOrigin, ErrNoToken, ErrBadToken} {
+		if got := webapp.ErrorStatus(err); got != http.StatusForbidden {
+			t.Errorf("%v should be reported as 403, got: %d", err, got)
+		}
+	}
+}
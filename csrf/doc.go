@@ -0,0 +1,68 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+
+Package csrf protects web applications against cross-site request forgery.
+
+Tokens
+
+The handler created with NewHandler keeps a random secret in a cookie and
+requires every request with an unsafe method, i.e. anything other than GET,
+HEAD, OPTIONS and TRACE, to submit a token derived from the secret, either in
+a form field or in a request header. Tokens are masked with a fresh one-time
+pad every time they are handed out, so that they cannot be recovered through
+compression side channels such as BREACH.
+
+In addition, the Origin header, or the Referer header if there is no Origin,
+of unsafe requests must match the scheme and host of the request or one of
+the trusted origins. Requests received through reverse proxies are only
+known to be HTTPS if the proxies are trusted with WithTrustedProxies.
+
+Templates
+
+Importing this package registers the following template functions with
+webapp.RegisterRequestTemplateFuncs, so they are available to all templates
+executed with webapp.ExecuteTemplate:
+
+	<form method="post">
+		{{csrfField}}
+		...
+	</form>
+
+csrfField renders a hidden input with the token, and csrfToken returns the
+token itself, e.g. for a meta tag.
+
+Single page applications
+
+In header mode, the token is sent in a response header of every response and
+is only accepted from the request header, so that JavaScript clients can
+echo it back:
+
+	fetch("/api/items", {
+		method: "POST",
+		headers: {"X-CSRF-Token": token},
+		body: JSON.stringify(item),
+	})
+
+Rejections in header mode are reported as JSON.
+
+Errors
+
+Rejected requests are passed to the error handler set with WithErrorHandler,
+which can render the reason returned by FailureReason. The reason is always
+one of ErrBadOrigin, ErrNoToken or ErrBadToken.
+
+*/
+package csrf
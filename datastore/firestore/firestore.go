@@ -17,6 +17,7 @@ package firestore
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/qqiao/pipeline/v2"
@@ -24,6 +25,29 @@ import (
 	"google.golang.org/api/iterator"
 )
 
+// DefaultPingTimeout is the maximum time Ping waits for firestore to respond.
+const DefaultPingTimeout = 2 * time.Second
+
+// Ping checks that the given collection can be read, by fetching at most one
+// document from it. The check is bounded by DefaultPingTimeout, or by the
+// deadline of ctx if it is earlier.
+//
+// An empty collection is not an error, only failing to reach firestore or to
+// read the collection is.
+func Ping(ctx context.Context, client *firestore.Client,
+	collectionName string) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultPingTimeout)
+	defer cancel()
+
+	iter := client.Collection(collectionName).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	if _, err := iter.Next(); err != nil && err != iterator.Done {
+		return err
+	}
+	return nil
+}
+
 // ApplyQuery takes collection reference and a custom query and applies the
 // query to the collection reference.
 func ApplyQuery(col *firestore.CollectionRef,
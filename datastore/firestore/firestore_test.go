@@ -109,3 +109,21 @@ func TestOr(t *testing.T) {
 		return v
 	})
 }
+
+func TestPing(t *testing.T) {
+	if err := fs.Ping(context.Background(), client,
+		collectionNameOrTest); err != nil {
+		t.Errorf("Ping should succeed: %v", err)
+	}
+
+	if err := fs.Ping(context.Background(), client,
+		"test_empty_collection"); err != nil {
+		t.Errorf("Ping should succeed on empty collections: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := fs.Ping(ctx, client, collectionNameOrTest); err == nil {
+		t.Error("Ping should fail with a cancelled context")
+	}
+}
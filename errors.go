@@ -0,0 +1,249 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// errorStatus maps an error to the HTTP status code it is reported with.
+type errorStatus struct {
+	target error
+	status int
+}
+
+var (
+	errorStatusesMu sync.RWMutex
+	errorStatuses   []errorStatus
+)
+
+// HTTPStatusError is implemented by errors that know the HTTP status code
+// they should be reported with.
+type HTTPStatusError interface {
+	error
+	HTTPStatus() int
+}
+
+// RegisterErrorStatus registers the HTTP status code errors matching target,
+// as reported by errors.Is, are reported with, e.g.
+//
+//	webapp.RegisterErrorStatus(user.ErrUserNotFound, http.StatusNotFound)
+//	webapp.RegisterErrorStatus(rememberme.ErrTokenInvalid,
+//		http.StatusUnauthorized)
+//	webapp.RegisterErrorStatus(jwt.ErrTokenExpired, http.StatusUnauthorized)
+//
+// Registering a target again replaces its status code.
+//
+// HTTP middleware such as the csrf package registers its errors when it is
+// imported. The auth/user, auth/rememberme and jwt packages do not depend on
+// this package, so that they can be used without it, and their errors have to
+// be registered by the application as above.
+func RegisterErrorStatus(target error, status int) {
+	errorStatusesMu.Lock()
+	defer errorStatusesMu.Unlock()
+
+	for i, s := range errorStatuses {
+		if s.target == target {
+			errorStatuses[i].status = status
+			return
+		}
+	}
+	errorStatuses = append(errorStatuses, errorStatus{target, status})
+}
+
+// ErrorStatus returns the HTTP status code err is reported with.
+//
+// Errors implementing HTTPStatusError anywhere in the chain report their own
+// status code. Otherwise, the status code of the first registered target
+// matching err is used. Errors that match nothing are reported with 500
+// Internal Server Error.
+func ErrorStatus(err error) int {
+	var statusErr HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatus()
+	}
+
+	errorStatusesMu.RLock()
+	defer errorStatusesMu.RUnlock()
+
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.target) {
+			return s.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// Problem is a problem details object as defined in RFC 9457.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// ErrorPage is the data error templates are executed with.
+type ErrorPage struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Title is the standard text of the status code, e.g. Not Found.
+	Title string
+
+	// Detail is the message of the error. It is empty for server errors, so
+	// that internal details are not leaked.
+	Detail string
+
+	// Locale is the locale negotiated for the request.
+	Locale string
+
+	// RequestID is the ID of the request, as assigned by the handler created
+	// with NewLoggingHandler.
+	RequestID string
+}
+
+// ErrorRenderer reports errors to clients, as HTML pages to browsers and as
+// RFC 9457 application/problem+json to API clients, depending on the Accept
+// header of the request.
+//
+// The status code of an error is determined by ErrorStatus.
+type ErrorRenderer struct {
+	templatePath string
+}
+
+// NewErrorRenderer creates an error renderer. Until a template is set with
+// WithTemplate, HTML errors are rendered as plain text.
+func NewErrorRenderer() *ErrorRenderer {
+	return &ErrorRenderer{}
+}
+
+// WithTemplate sets the path of the HTML error template. The template is
+// loaded with LoadTemplate, executed with ExecuteTemplate, so functions bound
+// to the request such as the t function of the i18n package are localized
+// into the negotiated locale, and receives an ErrorPage as data.
+func (e *ErrorRenderer) WithTemplate(path string) *ErrorRenderer {
+	e.templatePath = path
+	return e
+}
+
+// Render reports err as the response to the request. Server errors are logged
+// along with the request ID.
+func (e *ErrorRenderer) Render(w http.ResponseWriter, r *http.Request,
+	err error) {
+	status := ErrorStatus(err)
+	page := ErrorPage{
+		Status:    status,
+		Title:     http.StatusText(status),
+		Locale:    LocaleFromContext(r.Context()),
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+	if status < http.StatusInternalServerError {
+		page.Detail = err.Error()
+	} else {
+		log.Printf("Error serving %s [%s]: %v", r.URL.Path, page.RequestID, err)
+	}
+
+	addVary(w.Header(), "Accept")
+	if prefersHTML(r.Header.Get("Accept")) {
+		e.renderHTML(w, r, page)
+		return
+	}
+	e.renderProblem(w, r, page)
+}
+
+// HandlerFunc adapts a handler that returns an error into an http.Handler
+// that renders the error, if any, with e.
+func (e *ErrorRenderer) HandlerFunc(
+	f func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			e.Render(w, r, err)
+		}
+	})
+}
+
+func (e *ErrorRenderer) renderHTML(w http.ResponseWriter, r *http.Request,
+	page ErrorPage) {
+	if e.templatePath == "" {
+		http.Error(w, errorText(page), page.Status)
+		return
+	}
+
+	var buf bytes.Buffer
+	tmpl, err := LoadTemplate(e.templatePath, false)
+	if err == nil {
+		err = ExecuteTemplate(&buf, r, tmpl, page)
+	}
+	if err != nil {
+		log.Printf("Unable to render error template: %v", err)
+		http.Error(w, errorText(page), page.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if page.Locale != "" {
+		w.Header().Set("Content-Language", page.Locale)
+	}
+	w.WriteHeader(page.Status)
+	w.Write(buf.Bytes())
+}
+
+func (e *ErrorRenderer) renderProblem(w http.ResponseWriter, r *http.Request,
+	page ErrorPage) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(page.Status)
+	if err := json.NewEncoder(w).Encode(Problem{
+		Type:     "about:blank",
+		Title:    page.Title,
+		Status:   page.Status,
+		Detail:   page.Detail,
+		Instance: r.URL.Path,
+	}); err != nil {
+		log.Printf("Unable to write problem details: %v", err)
+	}
+}
+
+// errorText returns the plain text form of an error page.
+func errorText(page ErrorPage) string {
+	if page.Detail == "" {
+		return page.Title
+	}
+	return page.Title + ": " + page.Detail
+}
+
+// prefersHTML reports whether a client with the given Accept header prefers
+// HTML over JSON. Browsers always list text/html explicitly, so HTML is only
+// chosen if it is acceptable without relying on */*, and at least as
+// preferred as JSON.
+func prefersHTML(accept string) bool {
+	htmlQuality, jsonQuality := 0.0, 0.0
+	for _, v := range parseQualityList(accept) {
+		switch strings.ToLower(v.value) {
+		case "text/html", "application/xhtml+xml", "text/*":
+			htmlQuality = max(htmlQuality, v.quality)
+		case "application/json", "application/problem+json", "application/*":
+			jsonQuality = max(jsonQuality, v.quality)
+		}
+	}
+	return htmlQuality > 0 && htmlQuality >= jsonQuality
+}
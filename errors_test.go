@@ -0,0 +1,208 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qqiao/webapp/v2/jwt"
+)
+
+var (
+	errTestNotFound = errors.New("widget not found")
+	errTestConflict = errors.New("widget conflict")
+)
+
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "short and stout" }
+func (teapotError) HTTPStatus() int { return http.StatusTeapot }
+
+func init() {
+	RegisterErrorStatus(errTestNotFound, http.StatusNotFound)
+	RegisterErrorStatus(errTestConflict, http.StatusBadRequest)
+	RegisterErrorStatus(errTestConflict, http.StatusConflict)
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{errTestNotFound, http.StatusNotFound},
+		{fmt.Errorf("loading: %w", errTestNotFound), http.StatusNotFound},
+		{errTestConflict, http.StatusConflict},
+		{fmt.Errorf("brewing: %w", teapotError{}), http.StatusTeapot},
+		{errors.New("unknown"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := ErrorStatus(test.err); got != test.expected {
+			t.Errorf("ErrorStatus(%v) should be %d, got: %d", test.err,
+				test.expected, got)
+		}
+	}
+}
+
+func TestErrorStatusExpiredToken(t *testing.T) {
+	errorStatusesMu.Lock()
+	statuses := slices.Clone(errorStatuses)
+	errorStatusesMu.Unlock()
+	t.Cleanup(func() {
+		errorStatusesMu.Lock()
+		errorStatuses = statuses
+		errorStatusesMu.Unlock()
+	})
+	RegisterErrorStatus(jwt.ErrTokenExpired, http.StatusUnauthorized)
+
+	manager, err := jwt.NewHMACManager("HS256", make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenCh, errCh := manager.SignCustom(jwt.NewClaims().
+		WithExpiry(time.Now().Add(-time.Minute)))
+	var token string
+	select {
+	case err := <-errCh:
+		t.Fatalf("Failed to create token: %v", err)
+	case token = <-tokenCh:
+	}
+
+	claimsCh, errCh := manager.ParseCustom(token)
+	select {
+	case err := <-errCh:
+		if got := ErrorStatus(err); got != http.StatusUnauthorized {
+			t.Errorf("Expired token should be reported with 401, got: %d "+
+				"(%v)", got, err)
+		}
+	case <-claimsCh:
+		t.Error("Expired token should not be valid")
+	}
+}
+
+func TestPrefersHTML(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			true},
+		{"application/json", false},
+		{"application/problem+json, text/html;q=0.5", false},
+		{"*/*", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := prefersHTML(test.accept); got != test.expected {
+			t.Errorf("prefersHTML(%q) should be %t, got: %t", test.accept,
+				test.expected, got)
+		}
+	}
+}
+
+func TestErrorRenderer(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"error.html": `<h1>{{.Status}} {{.Title}}</h1><p>{{.Detail}}</p>` +
+			`<p>{{.Locale}}</p>`,
+	})
+	renderer := NewErrorRenderer().
+		WithTemplate(filepath.Join(dir, "error.html"))
+
+	var handlerErr error
+	h := NewLocaleHandler(renderer.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) error {
+		return handlerErr
+	}), NewLocaleOptions("en", "fr"))
+
+	serve := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/fr/widgets/1", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	handlerErr = fmt.Errorf("loading: %w", errTestNotFound)
+	w := serve("text/html")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got: %d", w.Code)
+	}
+	expected := `<h1>404 Not Found</h1><p>loading: widget not found</p>` +
+		`<p>fr</p>`
+	if w.Body.String() != expected {
+		t.Errorf("Expected page %q, got: %q", expected, w.Body.String())
+	}
+	if w.Header().Get("Vary") == "" ||
+		!strings.Contains(w.Header().Get("Vary"), "Accept") {
+		t.Errorf("Vary should include Accept, got: %q", w.Header().Get("Vary"))
+	}
+
+	w = serve("application/json")
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected problem details, got: %q", ct)
+	}
+	var problem Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Title != "Not Found" ||
+		problem.Detail != "loading: widget not found" ||
+		problem.Instance != "/widgets/1" {
+		t.Errorf("Unexpected problem details: %+v", problem)
+	}
+
+	handlerErr = errors.New("database password is hunter2")
+	w = serve("text/html")
+	if w.Code != http.StatusInternalServerError ||
+		strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("Server errors should not leak details, got: %d %q", w.Code,
+			w.Body.String())
+	}
+
+	handlerErr = nil
+	if w = serve("text/html"); w.Code != http.StatusOK {
+		t.Errorf("Handlers without errors should be left alone, got: %d",
+			w.Code)
+	}
+}
+
+func TestErrorRendererFallback(t *testing.T) {
+	for _, renderer := range []*ErrorRenderer{
+		NewErrorRenderer(),
+		NewErrorRenderer().WithTemplate("testdata/does-not-exist.html"),
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "text/html")
+		renderer.Render(w, r, errTestNotFound)
+
+		if w.Code != http.StatusNotFound ||
+			!strings.HasPrefix(w.Body.String(), "Not Found: widget not found") {
+			t.Errorf("Expected plain text fallback, got: %d %q", w.Code,
+				w.Body.String())
+		}
+	}
+}
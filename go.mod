@@ -4,6 +4,9 @@ go 1.25.8
 
 require (
 	cloud.google.com/go/firestore v1.22.0
+	github.com/BurntSushi/toml v1.6.0
+	github.com/andybalholm/brotli v1.2.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/google/uuid v1.6.0
 	github.com/qqiao/pipeline/v2 v2.1.2
@@ -0,0 +1,108 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qqiao/webapp/v2/internal/httpheader"
+)
+
+// qualityValue is a single entry of a header that carries a weighted list of
+// values, such as Accept, Accept-Encoding or Accept-Language.
+type qualityValue struct {
+	value   string
+	quality float64
+}
+
+// parseQualityList parses a comma separated list of values with optional q
+// parameters as defined in RFC 9110 section 12.4.2.
+//
+// The returned values are sorted by descending quality. Values with the same
+// quality keep the order in which they appear in the header. Entries that are
+// empty or carry a malformed quality value are skipped. Parameters other than
+// q are discarded.
+func parseQualityList(header string) []qualityValue {
+	values := make([]qualityValue, 0)
+	for _, entry := range strings.Split(header, ",") {
+		params := strings.Split(entry, ";")
+		value := strings.TrimSpace(params[0])
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		valid := true
+		for _, param := range params[1:] {
+			name, val, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			quality = q
+		}
+		if !valid {
+			continue
+		}
+
+		values = append(values, qualityValue{value: value, quality: quality})
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].quality > values[j].quality
+	})
+	return values
+}
+
+// addVary adds the field to the Vary header unless it is already present.
+func addVary(header http.Header, field string) {
+	httpheader.AddVary(header, field)
+}
+
+// negotiateEncoding picks the content coding from available that the client
+// prefers according to the Accept-Encoding header. Codings with the same
+// quality are picked in the order of available. An empty string is returned
+// if none of the codings are acceptable, in which case the identity coding
+// should be used.
+func negotiateEncoding(header string, available ...string) string {
+	accepted := parseQualityList(header)
+	best, bestQuality := "", 0.0
+	for _, encoding := range available {
+		quality, wildcard := 0.0, -1.0
+		for _, v := range accepted {
+			if strings.EqualFold(v.value, encoding) {
+				quality, wildcard = v.quality, -1
+				break
+			}
+			if v.value == "*" && wildcard < 0 {
+				wildcard = v.quality
+			}
+		}
+		if wildcard >= 0 {
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// DefaultHSTSMaxAge is the max-age used by HSTSHandler, which is 2 years.
+const DefaultHSTSMaxAge = 63072000 * time.Second
+
+// HSTSOptions controls the Strict-Transport-Security policy sent by the
+// handler created with NewHSTSHandler.
+type HSTSOptions struct {
+	maxAge            time.Duration
+	includeSubDomains bool
+	preload           bool
+	trustedProxies    []netip.Prefix
+}
+
+// NewHSTSOptions creates HSTS options with the same policy as HSTSHandler:
+// a max-age of 2 years, with includeSubDomains and preload.
+func NewHSTSOptions() *HSTSOptions {
+	return &HSTSOptions{
+		maxAge:            DefaultHSTSMaxAge,
+		includeSubDomains: true,
+		preload:           true,
+	}
+}
+
+// WithMaxAge sets how long browsers should remember to only access the site
+// over HTTPS. The value is truncated to whole seconds. A max-age of 0 tells
+// browsers to forget the policy.
+func (o *HSTSOptions) WithMaxAge(maxAge time.Duration) *HSTSOptions {
+	o.maxAge = maxAge
+	return o
+}
+
+// WithIncludeSubDomains sets whether the policy also applies to all
+// subdomains.
+func (o *HSTSOptions) WithIncludeSubDomains(includeSubDomains bool) *HSTSOptions {
+	o.includeSubDomains = includeSubDomains
+	return o
+}
+
+// WithPreload sets whether the preload directive is sent.
+//
+// Please note that the preload list requires a max-age of at least 1 year and
+// includeSubDomains. Domains that must not be submitted to the preload list
+// should disable this.
+func (o *HSTSOptions) WithPreload(preload bool) *HSTSOptions {
+	o.preload = preload
+	return o
+}
+
+// WithTrustedProxies sets the addresses of the reverse proxies whose
+// Forwarded and X-Forwarded-Proto headers are trusted to tell whether the
+// original request was made over HTTPS.
+func (o *HSTSOptions) WithTrustedProxies(prefixes ...netip.Prefix) *HSTSOptions {
+	o.trustedProxies = prefixes
+	return o
+}
+
+// String returns the value of the Strict-Transport-Security header.
+func (o *HSTSOptions) String() string {
+	value := "max-age=" + strconv.FormatInt(int64(o.maxAge/time.Second), 10)
+	if o.includeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if o.preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// NewHSTSHandler takes a normal HTTP handler and adds the capability of
+// sending HSTS headers with the given policy.
+//
+// As required by RFC 6797, the header is only sent on secure requests. A
+// request is considered secure if it is received over TLS, or if it comes
+// from one of the trusted proxies with either Forwarded or X-Forwarded-Proto
+// saying that the protocol is https.
+func NewHSTSHandler(h http.Handler, opts *HSTSOptions) http.Handler {
+	value, trustedProxies := opts.String(), opts.trustedProxies
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSecureRequest(r, trustedProxies) {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
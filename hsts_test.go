@@ -0,0 +1,93 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+})
+
+func TestHSTSOptions(t *testing.T) {
+	tests := []struct {
+		opts     *HSTSOptions
+		expected string
+	}{
+		{NewHSTSOptions(), "max-age=63072000; includeSubDomains; preload"},
+		{NewHSTSOptions().WithPreload(false),
+			"max-age=63072000; includeSubDomains"},
+		{NewHSTSOptions().WithMaxAge(time.Hour).WithIncludeSubDomains(false).
+			WithPreload(false), "max-age=3600"},
+		{NewHSTSOptions().WithMaxAge(0), "max-age=0; includeSubDomains; preload"},
+	}
+
+	for _, test := range tests {
+		if got := test.opts.String(); got != test.expected {
+			t.Errorf("Expected %q, got: %q", test.expected, got)
+		}
+	}
+}
+
+func TestHSTSHandler(t *testing.T) {
+	h := NewHSTSHandler(okHandler, NewHSTSOptions().WithPreload(false).
+		WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8")))
+
+	newRequest := func(remoteAddr string, proto string, secure bool) *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remoteAddr
+		if proto != "" {
+			r.Header.Set("X-Forwarded-Proto", proto)
+		}
+		if secure {
+			r.TLS = &tls.ConnectionState{}
+		}
+		return r
+	}
+
+	tests := map[string]struct {
+		r        *http.Request
+		expected bool
+	}{
+		"TLS":                 {newRequest("192.0.2.1:1234", "", true), true},
+		"Plain HTTP":          {newRequest("192.0.2.1:1234", "", false), false},
+		"Untrusted proxy":     {newRequest("192.0.2.1:1234", "https", false), false},
+		"Trusted proxy":       {newRequest("10.1.2.3:1234", "https", false), true},
+		"Trusted proxy, HTTP": {newRequest("10.1.2.3:1234", "http", false), false},
+		"Last value wins":     {newRequest("10.1.2.3:1234", "http, https", false), true},
+	}
+
+	for name, test := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, test.r)
+
+		got := w.Header().Get("Strict-Transport-Security")
+		if test.expected && got != "max-age=63072000; includeSubDomains" {
+			t.Errorf("%s: expected HSTS header, got: %q", name, got)
+		}
+		if !test.expected && got != "" {
+			t.Errorf("%s: expected no HSTS header, got: %q", name, got)
+		}
+		if w.Body.String() != "ok" {
+			t.Errorf("%s: handler should have been called", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ACMEChallengePath is the path prefix used by ACME HTTP-01 challenges, which
+// must be served over plain HTTP.
+const ACMEChallengePath = "/.well-known/acme-challenge/"
+
+// HTTPSRedirectOptions controls the behaviour of the handler created with
+// NewHTTPSRedirectHandler.
+type HTTPSRedirectOptions struct {
+	host           string
+	port           int
+	exemptPaths    []string
+	trustedProxies []netip.Prefix
+}
+
+// NewHTTPSRedirectOptions creates HTTPS redirect options. By default, only
+// ACME challenges are exempt from redirection.
+func NewHTTPSRedirectOptions() *HTTPSRedirectOptions {
+	return &HTTPSRedirectOptions{
+		exemptPaths: []string{ACMEChallengePath},
+	}
+}
+
+// WithHost sets the host requests are redirected to. By default, the host of
+// the request is used.
+func (o *HTTPSRedirectOptions) WithHost(host string) *HTTPSRedirectOptions {
+	o.host = host
+	return o
+}
+
+// WithHTTPSPort sets the port HTTPS is served on, if it is not the default
+// port 443.
+func (o *HTTPSRedirectOptions) WithHTTPSPort(port int) *HTTPSRedirectOptions {
+	o.port = port
+	return o
+}
+
+// WithExemptPaths sets the paths that are never redirected, such as health
+// checks. Paths ending with a slash match all the paths under them, other
+// paths must match exactly.
+//
+// The given paths replace the default ones, ACMEChallengePath has to be
+// included again if ACME challenges are still served by the handler.
+func (o *HTTPSRedirectOptions) WithExemptPaths(paths ...string) *HTTPSRedirectOptions {
+	o.exemptPaths = paths
+	return o
+}
+
+// WithTrustedProxies sets the addresses of the reverse proxies whose
+// Forwarded and X-Forwarded-Proto headers are trusted to tell whether the
+// original request was made over HTTPS.
+//
+// Requests from any other address are judged by whether they are received
+// over TLS only, so that clients cannot skip the redirect by sending the
+// headers themselves.
+func (o *HTTPSRedirectOptions) WithTrustedProxies(
+	prefixes ...netip.Prefix) *HTTPSRedirectOptions {
+	o.trustedProxies = prefixes
+	return o
+}
+
+// NewHTTPSRedirectHandler takes a normal HTTP handler and redirects all plain
+// HTTP requests to their HTTPS equivalent instead of calling it. Secure
+// requests and requests for exempt paths are passed on to the handler.
+//
+// GET and HEAD requests are redirected with 301 Moved Permanently, all other
+// requests with 308 Permanent Redirect so that the method and body are
+// preserved. Requests without a Host header are rejected with 400 Bad Request
+// unless a host is set with WithHost.
+func NewHTTPSRedirectHandler(h http.Handler, opts *HTTPSRedirectOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSecureRequest(r, opts.trustedProxies) || opts.exempt(r.URL.Path) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		target := opts.target(r.Host)
+		if target == "" {
+			http.Error(w, http.StatusText(http.StatusBadRequest),
+				http.StatusBadRequest)
+			return
+		}
+
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+target+r.URL.RequestURI(), status)
+	})
+}
+
+// exempt reports whether the path is exempt from redirection.
+func (o *HTTPSRedirectOptions) exempt(path string) bool {
+	for _, exempt := range o.exemptPaths {
+		if path == exempt ||
+			(strings.HasSuffix(exempt, "/") && strings.HasPrefix(path, exempt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// target returns the host, with the port if necessary, that a request for
+// the given host is redirected to. An empty string is returned if there is
+// no host to redirect to.
+func (o *HTTPSRedirectOptions) target(host string) string {
+	if o.host != "" {
+		host = o.host
+	} else if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	if host == "" {
+		return ""
+	}
+
+	if o.port != 0 && o.port != 443 {
+		return net.JoinHostPort(host, strconv.Itoa(o.port))
+	}
+	if strings.Contains(host, ":") {
+		// IPv6 literals must be enclosed in brackets.
+		return "[" + host + "]"
+	}
+	return host
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	h := NewHTTPSRedirectHandler(okHandler, NewHTTPSRedirectOptions().
+		WithExemptPaths(ACMEChallengePath, "/healthz").
+		WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("::1/128")))
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		remote   string
+		header   map[string]string
+		tls      bool
+		status   int
+		location string
+	}{
+		{"GET should use 301", "GET", "http://example.com:8080/a?b=c",
+			"192.0.2.1:1", nil, false, http.StatusMovedPermanently,
+			"https://example.com/a?b=c"},
+		{"POST should use 308", "POST", "http://example.com/form",
+			"192.0.2.1:1", nil, false, http.StatusPermanentRedirect,
+			"https://example.com/form"},
+		{"TLS should pass", "GET", "https://example.com/", "192.0.2.1:1", nil,
+			true, http.StatusOK, ""},
+		{"Health checks should be exempt", "GET", "http://example.com/healthz",
+			"192.0.2.1:1", nil, false, http.StatusOK, ""},
+		{"ACME challenges should be exempt", "GET",
+			"http://example.com/.well-known/acme-challenge/token", "192.0.2.1:1",
+			nil, false, http.StatusOK, ""},
+		{"Exact exempt paths should not match sub-paths", "GET",
+			"http://example.com/healthz/x", "192.0.2.1:1", nil, false,
+			http.StatusMovedPermanently, "https://example.com/healthz/x"},
+		{"Untrusted X-Forwarded-Proto should be ignored", "GET",
+			"http://example.com/", "192.0.2.1:1",
+			map[string]string{"X-Forwarded-Proto": "https"}, false,
+			http.StatusMovedPermanently, "https://example.com/"},
+		{"Trusted X-Forwarded-Proto should be honoured", "GET",
+			"http://example.com/", "10.0.0.1:1",
+			map[string]string{"X-Forwarded-Proto": "https"}, false,
+			http.StatusOK, ""},
+		{"Trusted Forwarded should be honoured", "GET", "http://example.com/",
+			"[::1]:1", map[string]string{
+				"Forwarded": `for=192.0.2.1;proto=http, for="[::1]";proto=https`,
+			}, false, http.StatusOK, ""},
+		{"Forwarded should take precedence", "GET", "http://example.com/",
+			"10.0.0.1:1", map[string]string{
+				"Forwarded":         "proto=http",
+				"X-Forwarded-Proto": "https",
+			}, false, http.StatusMovedPermanently, "https://example.com/"},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.target, nil)
+		r.RemoteAddr = test.remote
+		for name, value := range test.header {
+			r.Header.Set(name, value)
+		}
+		if test.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got: %d", test.name, test.status,
+				w.Code)
+		}
+		if got := w.Header().Get("Location"); got != test.location {
+			t.Errorf("%s: expected location %q, got: %q", test.name,
+				test.location, got)
+		}
+	}
+}
+
+func TestHTTPSRedirectHandlerNoHost(t *testing.T) {
+	tests := []struct {
+		opts     *HTTPSRedirectOptions
+		status   int
+		location string
+	}{
+		{NewHTTPSRedirectOptions(), http.StatusBadRequest, ""},
+		{NewHTTPSRedirectOptions().WithHost("www.example.com"),
+			http.StatusMovedPermanently, "https://www.example.com/a"},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/a", nil)
+		r.Host = ""
+
+		w := httptest.NewRecorder()
+		NewHTTPSRedirectHandler(okHandler, test.opts).ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("Expected status %d, got: %d", test.status, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != test.location {
+			t.Errorf("Expected location %q, got: %q", test.location, got)
+		}
+	}
+}
+
+func TestHTTPSRedirectTarget(t *testing.T) {
+	tests := []struct {
+		opts     *HTTPSRedirectOptions
+		host     string
+		expected string
+	}{
+		{NewHTTPSRedirectOptions(), "example.com", "example.com"},
+		{NewHTTPSRedirectOptions(), "[::1]:80", "[::1]"},
+		{NewHTTPSRedirectOptions(), "[::1]", "[::1]"},
+		{NewHTTPSRedirectOptions().WithHTTPSPort(8443), "example.com:8080",
+			"example.com:8443"},
+		{NewHTTPSRedirectOptions().WithHost("www.example.com"), "example.com",
+			"www.example.com"},
+		{NewHTTPSRedirectOptions(), "", ""},
+		{NewHTTPSRedirectOptions(), ":80", ""},
+		{NewHTTPSRedirectOptions().WithHost("www.example.com"), "",
+			"www.example.com"},
+	}
+
+	for _, test := range tests {
+		if got := test.opts.target(test.host); got != test.expected {
+			t.Errorf("target(%q) should return %q, got: %q", test.host,
+				test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/qqiao/webapp/v2"
+)
+
+// Errors.
+var (
+	ErrInvalidMessage    = errors.New("invalid message")
+	ErrUnsupportedFormat = errors.New("unsupported translation file format")
+)
+
+// Message is a translated message, keyed by plural category. Messages without
+// plural forms only have the PluralOther form.
+type Message map[PluralCategory]string
+
+// Catalog holds the translated messages of all the supported locales.
+//
+// A Catalog is safe for concurrent use.
+type Catalog struct {
+	defaultLocale string
+
+	mu       sync.RWMutex
+	locales  []string
+	messages map[string]map[string]Message
+}
+
+// NewCatalog creates an empty catalog. Messages not found in any less
+// specific locale are looked up in the default locale.
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]Message),
+	}
+}
+
+// Add adds a message for the given locale to the catalog, replacing any
+// existing message with the same key.
+func (c *Catalog) Add(locale string, key string, message Message) *Catalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(locale, map[string]Message{key: message})
+	return c
+}
+
+// Load loads all the translation files matching the given patterns from
+// fsys. The syntax of patterns is the same as in fs.Glob.
+//
+// The format of each file is determined by its extension, which must be
+// either .json or .toml. The locale is the file name without the extension.
+func (c *Catalog) Load(fsys fs.FS, patterns ...string) error {
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			messages, err := readMessages(fsys, name)
+			if err != nil {
+				return fmt.Errorf("unable to load %s: %w", name, err)
+			}
+
+			locale := strings.TrimSuffix(path.Base(name), path.Ext(name))
+			c.mu.Lock()
+			c.add(locale, messages)
+			c.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Locales returns all the locales that have messages in the catalog, with
+// the default locale first. The result can be used as the available locales
+// for webapp.NewLocaleOptions.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := []string{c.defaultLocale}
+	for _, locale := range c.locales {
+		if !strings.EqualFold(locale, c.defaultLocale) {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// Lookup finds the message for the given key. Less specific locales and the
+// default locale are tried in turn if the message does not exist for the
+// locale. The locale the message is found in is returned along with the
+// message.
+func (c *Catalog) Lookup(locale string, key string) (Message, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range fallbackLocales(locale, c.defaultLocale) {
+		if message, has := c.messages[strings.ToLower(candidate)][key]; has {
+			return message, candidate, true
+		}
+	}
+	return nil, "", false
+}
+
+// T translates the message with the given key into the locale.
+//
+// Arguments are interpreted as follows:
+//   - A number is the count used for choosing the plural form, it is also
+//     available as the {count} placeholder.
+//   - A string is the name of a placeholder, and the argument following it is
+//     the value.
+//   - A map[string]any or map[string]string holds placeholder values.
+//
+// If the message cannot be found, the key is returned.
+func (c *Catalog) T(locale string, key string, args ...any) string {
+	message, found, has := c.Lookup(locale, key)
+	if !has {
+		return key
+	}
+
+	params := make(map[string]any)
+	count, hasCount := 0.0, false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case string:
+			if i+1 < len(args) {
+				params[arg] = args[i+1]
+				i++
+			}
+		case map[string]any:
+			for name, value := range arg {
+				params[name] = value
+			}
+		case map[string]string:
+			for name, value := range arg {
+				params[name] = value
+			}
+		default:
+			if n, ok := toFloat(arg); ok && !hasCount {
+				count, hasCount = n, true
+				params["count"] = arg
+			}
+		}
+	}
+
+	form := message[PluralOther]
+	if hasCount {
+		if f, has := message[Plural(found, count)]; has {
+			form = f
+		}
+	}
+	return replacePlaceholders(form, params)
+}
+
+// TemplateFuncs returns the template functions of the catalog bound to the
+// locale negotiated for the request. It can be passed directly to
+// webapp.RegisterRequestTemplateFuncs.
+//
+// The following functions are provided:
+//   - t: translates a message, it takes the same arguments as Catalog.T
+//     without the locale.
+func (c *Catalog) TemplateFuncs(r *http.Request) template.FuncMap {
+	locale := webapp.LocaleFromContext(r.Context())
+	return template.FuncMap{
+		"t": func(key string, args ...any) string {
+			return c.T(locale, key, args...)
+		},
+	}
+}
+
+// add adds messages for the locale. The caller must hold the write lock.
+func (c *Catalog) add(locale string, messages map[string]Message) {
+	key := strings.ToLower(locale)
+	existing, has := c.messages[key]
+	if !has {
+		existing = make(map[string]Message)
+		c.messages[key] = existing
+		c.locales = append(c.locales, locale)
+	}
+	for k, message := range messages {
+		existing[k] = message
+	}
+}
+
+// fallbackLocales returns the chain of locales to try for the given locale,
+// from the most specific to the default locale.
+func fallbackLocales(locale string, defaultLocale string) []string {
+	locales := make([]string, 0)
+	for locale != "" {
+		locales = append(locales, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(locales, defaultLocale)
+}
+
+// readMessages reads and decodes a single translation file.
+func readMessages(fsys fs.FS, name string) (map[string]Message, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]any)
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		err = ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]Message, len(raw))
+	for key, value := range raw {
+		message, err := toMessage(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		messages[key] = message
+	}
+	return messages, nil
+}
+
+// toMessage converts a decoded value into a message.
+func toMessage(value any) (Message, error) {
+	switch v := value.(type) {
+	case string:
+		return Message{PluralOther: v}, nil
+	case map[string]any:
+		message := make(Message, len(v))
+		for category, form := range v {
+			s, ok := form.(string)
+			if !ok || !validCategory(PluralCategory(category)) {
+				return nil, ErrInvalidMessage
+			}
+			message[PluralCategory(category)] = s
+		}
+		if _, has := message[PluralOther]; !has {
+			return nil, ErrInvalidMessage
+		}
+		return message, nil
+	default:
+		return nil, ErrInvalidMessage
+	}
+}
+
+func validCategory(category PluralCategory) bool {
+	switch category {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+// replacePlaceholders replaces every {name} in s with the value of the named
+// parameter. Placeholders without a value are left untouched.
+func replacePlaceholders(s string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(s, "{") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(s[:start])
+		if value, has := params[s[start+1:end]]; has {
+			fmt.Fprint(&b, value)
+		} else {
+			b.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// toFloat converts any numeric value into a float64.
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/qqiao/webapp/v2"
+	"github.com/qqiao/webapp/v2/i18n"
+)
+
+var translations = fstest.MapFS{
+	"locales/en.json": {Data: []byte(`{
+		"greeting": "Hello, {name}!",
+		"items": {"one": "{count} item", "other": "{count} items"},
+		"only_en": "English only"
+	}`)},
+	"locales/zh.toml": {Data: []byte(`
+greeting = "{name}，你好！"
+items = { other = "{count} 个项目" }
+traditional = "简体"
+`)},
+	"locales/zh-TW.json": {Data: []byte(`{
+		"traditional": "繁體"
+	}`)},
+	"locales/ru.json": {Data: []byte(`{
+		"files": {
+			"one": "{count} файл",
+			"few": "{count} файла",
+			"many": "{count} файлов",
+			"other": "{count} файла"
+		}
+	}`)},
+}
+
+func newCatalog(t *testing.T) *i18n.Catalog {
+	c := i18n.NewCatalog("en")
+	if err := c.Load(translations, "locales/*.json", "locales/*.toml"); err != nil {
+		t.Fatalf("Unable to load translations: %v", err)
+	}
+	return c
+}
+
+func TestCatalog(t *testing.T) {
+	c := newCatalog(t)
+
+	tests := []struct {
+		locale   string
+		key      string
+		args     []any
+		expected string
+	}{
+		{"en", "greeting", []any{"name", "Bob"}, "Hello, Bob!"},
+		{"en", "greeting", []any{map[string]string{"name": "Bob"}}, "Hello, Bob!"},
+		{"en", "greeting", nil, "Hello, {name}!"},
+		{"en", "items", []any{1}, "1 item"},
+		{"en", "items", []any{2}, "2 items"},
+		{"en", "items", []any{1.5}, "1.5 items"},
+		{"zh", "items", []any{1}, "1 个项目"},
+		{"zh-TW", "traditional", nil, "繁體"},
+		{"zh-tw", "traditional", nil, "繁體"},
+		{"zh-HK", "traditional", nil, "简体"},
+		{"zh-TW", "greeting", []any{"name", "小明"}, "小明，你好！"},
+		{"zh-TW", "only_en", nil, "English only"},
+		{"ru", "files", []any{1}, "1 файл"},
+		{"ru", "files", []any{3}, "3 файла"},
+		{"ru", "files", []any{11}, "11 файлов"},
+		{"ru", "files", []any{22}, "22 файла"},
+		{"en", "missing", nil, "missing"},
+	}
+
+	for _, test := range tests {
+		if got := c.T(test.locale, test.key, test.args...); got != test.expected {
+			t.Errorf("T(%q, %q, %v) should return %q, got: %q", test.locale,
+				test.key, test.args, test.expected, got)
+		}
+	}
+}
+
+func TestCatalogLocales(t *testing.T) {
+	locales := newCatalog(t).Locales()
+	if len(locales) != 4 || locales[0] != "en" {
+		t.Errorf("Expected 4 locales with en first, got: %v", locales)
+	}
+}
+
+func TestCatalogLoadErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.yaml":    {Data: []byte("greeting: Hello")},
+		"fr.json":    {Data: []byte(`{"items": {"one": "un"}}`)},
+		"de.json":    {Data: []byte(`{"items": {"lots": "viele", "other": "x"}}`)},
+		"broken.txt": {Data: []byte(`{`)},
+	}
+
+	err := i18n.NewCatalog("en").Load(fsys, "*.yaml")
+	if !errors.Is(err, i18n.ErrUnsupportedFormat) {
+		t.Errorf("Expecting ErrUnsupportedFormat, got: %v", err)
+	}
+
+	for _, name := range []string{"fr.json", "de.json"} {
+		err = i18n.NewCatalog("en").Load(fsys, name)
+		if !errors.Is(err, i18n.ErrInvalidMessage) {
+			t.Errorf("Expecting ErrInvalidMessage for %s, got: %v", name, err)
+		}
+	}
+}
+
+func TestCatalogTemplateFuncs(t *testing.T) {
+	c := newCatalog(t)
+	webapp.RegisterRequestTemplateFuncs(c.TemplateFuncs)
+
+	path := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(path,
+		[]byte(`{{t "items" .Count}}|{{t "traditional"}}`), 0600); err != nil {
+		t.Fatalf("Unable to write template: %v", err)
+	}
+	tmpl := webapp.GetTemplate(path, false)
+
+	for locale, expected := range map[string]string{
+		"en":    "3 items|traditional",
+		"zh-TW": "3 个项目|繁體",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r = r.WithContext(webapp.ContextWithLocale(r.Context(), locale))
+
+		var buf bytes.Buffer
+		if err := webapp.ExecuteTemplate(&buf, r, tmpl,
+			map[string]int{"Count": 3}); err != nil {
+			t.Fatalf("Unable to execute template: %v", err)
+		}
+		if buf.String() != expected {
+			t.Errorf("Expected %q for %s, got: %q", expected, locale, buf.String())
+		}
+	}
+}
+
+func ExampleCatalog_T() {
+	c := i18n.NewCatalog("en").
+		Add("en", "items", i18n.Message{
+			i18n.PluralOne:   "{count} item",
+			i18n.PluralOther: "{count} items",
+		})
+
+	fmt.Println(c.T("en-GB", "items", 1))
+	fmt.Println(c.T("en-GB", "items", 5))
+
+	// Output:
+	// 1 item
+	// 5 items
+}
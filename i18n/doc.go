@@ -0,0 +1,53 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+
+Package i18n implements message catalogs for translating strings into the
+locale negotiated by the webapp package.
+
+Translation files
+
+Translations are loaded from JSON or TOML files, one file per locale. The
+locale is taken from the file name, e.g. zh-TW.json or en.toml. Each file maps
+message keys to either a plain string, or a table of CLDR plural categories:
+
+	{
+		"greeting": "Hello, {name}!",
+		"items": {
+			"one": "{count} item",
+			"other": "{count} items"
+		}
+	}
+
+Placeholders in curly braces are replaced by named arguments. The {count}
+placeholder holds the number used for choosing the plural form.
+
+Fallback
+
+If a message is not found for a locale, less specific locales are tried
+before the default locale of the catalog, e.g. zh-TW, then zh, then the
+default. If the message still cannot be found, the key itself is returned.
+
+Templates
+
+Catalog.TemplateFuncs provides a t function bound to the locale of the
+request, it can be registered with webapp.RegisterRequestTemplateFuncs so
+that templates can call:
+
+	{{t "items" .Count}}
+	{{t "greeting" "name" .Name}}
+
+*/
+package i18n
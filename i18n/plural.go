@@ -0,0 +1,166 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+// PluralCategory represents a CLDR plural category.
+type PluralCategory string
+
+// CLDR plural categories.
+const (
+	PluralZero  PluralCategory = "zero"
+	PluralOne   PluralCategory = "one"
+	PluralTwo   PluralCategory = "two"
+	PluralFew   PluralCategory = "few"
+	PluralMany  PluralCategory = "many"
+	PluralOther PluralCategory = "other"
+)
+
+// pluralRule returns the plural category of a number. i is the integer part
+// of the number and integer reports whether the number has no fraction
+// digits.
+type pluralRule func(i int64, integer bool) PluralCategory
+
+var pluralRules = map[string]pluralRule{}
+
+func init() {
+	register := func(rule pluralRule, languages ...string) {
+		for _, language := range languages {
+			pluralRules[language] = rule
+		}
+	}
+
+	register(pluralRuleNone, "id", "ja", "km", "ko", "lo", "ms", "my", "th",
+		"vi", "yue", "zh")
+	register(pluralRuleOneInteger, "bg", "ca", "da", "de", "el", "en", "es",
+		"et", "eu", "fi", "gl", "hu", "it", "nb", "nl", "nn", "no", "sv", "tr")
+	register(pluralRuleZeroOne, "fr", "hy", "pt")
+	register(pluralRuleEastSlavic, "be", "ru", "uk")
+	register(pluralRulePolish, "pl")
+	register(pluralRuleCzech, "cs", "sk")
+	register(pluralRuleArabic, "ar")
+	register(pluralRuleHebrew, "he", "iw")
+}
+
+// Plural returns the CLDR plural category of the given count for the locale.
+//
+// Only the integer and fraction part of the count are taken into account, so
+// categories that depend on the number of visible fraction digits are
+// approximated. Locales without a known rule use the English rule.
+func Plural(locale string, count float64) PluralCategory {
+	language, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	rule, has := pluralRules[language]
+	if !has {
+		rule = pluralRuleOneInteger
+	}
+
+	abs := math.Abs(count)
+	i := int64(abs)
+	return rule(i, float64(i) == abs)
+}
+
+func pluralRuleNone(int64, bool) PluralCategory {
+	return PluralOther
+}
+
+func pluralRuleOneInteger(i int64, integer bool) PluralCategory {
+	if i == 1 && integer {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralRuleZeroOne(i int64, _ bool) PluralCategory {
+	if i == 0 || i == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralRuleEastSlavic(i int64, integer bool) PluralCategory {
+	if !integer {
+		return PluralOther
+	}
+	switch mod10, mod100 := i%10, i%100; {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralRulePolish(i int64, integer bool) PluralCategory {
+	if !integer {
+		return PluralOther
+	}
+	switch mod10, mod100 := i%10, i%100; {
+	case i == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralRuleCzech(i int64, integer bool) PluralCategory {
+	switch {
+	case !integer:
+		return PluralMany
+	case i == 1:
+		return PluralOne
+	case i >= 2 && i <= 4:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func pluralRuleArabic(i int64, integer bool) PluralCategory {
+	if !integer {
+		return PluralOther
+	}
+	switch mod100 := i % 100; {
+	case i == 0:
+		return PluralZero
+	case i == 1:
+		return PluralOne
+	case i == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
+
+func pluralRuleHebrew(i int64, integer bool) PluralCategory {
+	switch {
+	case i == 1 && integer:
+		return PluralOne
+	case i == 2 && integer:
+		return PluralTwo
+	default:
+		return PluralOther
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/qqiao/webapp/v2/i18n"
+)
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		locale   string
+		count    float64
+		expected i18n.PluralCategory
+	}{
+		{"en", 1, i18n.PluralOne},
+		{"en-US", 0, i18n.PluralOther},
+		{"en", 1.5, i18n.PluralOther},
+		{"unknown", 1, i18n.PluralOne},
+		{"zh-TW", 1, i18n.PluralOther},
+		{"fr", 0, i18n.PluralOne},
+		{"fr", 1.5, i18n.PluralOne},
+		{"fr", 2, i18n.PluralOther},
+		{"ru", 21, i18n.PluralOne},
+		{"ru", 11, i18n.PluralMany},
+		{"ru", 24, i18n.PluralFew},
+		{"ru", 14, i18n.PluralMany},
+		{"ru", 1.5, i18n.PluralOther},
+		{"pl", 1, i18n.PluralOne},
+		{"pl", 21, i18n.PluralMany},
+		{"pl", 22, i18n.PluralFew},
+		{"cs", 3, i18n.PluralFew},
+		{"cs", 5, i18n.PluralOther},
+		{"ar", 0, i18n.PluralZero},
+		{"ar", 2, i18n.PluralTwo},
+		{"ar", 105, i18n.PluralFew},
+		{"ar", 111, i18n.PluralMany},
+		{"ar", 100, i18n.PluralOther},
+		{"he", 2, i18n.PluralTwo},
+	}
+
+	for _, test := range tests {
+		if got := i18n.Plural(test.locale, test.count); got != test.expected {
+			t.Errorf("Plural(%q, %v) should return %q, got: %q", test.locale,
+				test.count, test.expected, got)
+		}
+	}
+}
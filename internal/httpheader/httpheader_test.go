@@ -0,0 +1,42 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpheader
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestAddVary(t *testing.T) {
+	tests := []struct {
+		existing []string
+		expected []string
+	}{
+		{nil, []string{"Cookie"}},
+		{[]string{"Accept-Encoding"}, []string{"Accept-Encoding", "Cookie"}},
+		{[]string{"Accept-Encoding, cookie"}, []string{"Accept-Encoding, cookie"}},
+		{[]string{"*"}, []string{"*"}},
+	}
+
+	for _, test := range tests {
+		header := http.Header{"Vary": test.existing}
+		AddVary(header, "Cookie")
+		if got := header.Values("Vary"); !slices.Equal(got, test.expected) {
+			t.Errorf("AddVary(%q) should result in %q, got: %q", test.existing,
+				test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxy interprets the headers added by reverse proxies, shared by the
+// packages of this module.
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IsSecureRequest reports whether the request was made over HTTPS.
+//
+// Requests received over TLS are always secure. Otherwise, the Forwarded and
+// X-Forwarded-Proto headers are consulted, in that order, but only if the
+// request comes directly from one of the trusted proxies. If a header has
+// multiple values, the last one, which is the one added by the trusted proxy,
+// is used.
+func IsSecureRequest(r *http.Request, trustedProxies []netip.Prefix) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !fromTrustedProxy(r, trustedProxies) {
+		return false
+	}
+
+	proto := forwardedProto(lastValue(r.Header.Values("Forwarded")))
+	if proto == "" {
+		proto = lastValue(r.Header.Values("X-Forwarded-Proto"))
+	}
+	return strings.EqualFold(proto, "https")
+}
+
+// forwardedProto returns the proto parameter of a single Forwarded element as
+// defined in RFC 7239.
+func forwardedProto(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && strings.EqualFold(name, "proto") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// fromTrustedProxy reports whether the immediate peer of the request is
+// within one of the trusted prefixes.
+func fromTrustedProxy(r *http.Request, trustedProxies []netip.Prefix) bool {
+	if len(trustedProxies) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastValue returns the last element of a list of comma separated header
+// values.
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	list := values[len(values)-1]
+	if i := strings.LastIndex(list, ","); i >= 0 {
+		list = list[i+1:]
+	}
+	return strings.TrimSpace(list)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsSecureRequest(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		header     map[string][]string
+		expected   bool
+	}{
+		{"TLS requests should be secure", "192.0.2.1:1234", true, nil, true},
+		{"Plain requests should not be secure", "192.0.2.1:1234", false, nil,
+			false},
+		{"Forwarded should be trusted from proxies", "10.0.0.1:1234", false,
+			map[string][]string{"Forwarded": {"for=192.0.2.1;proto=https"}},
+			true},
+		{"X-Forwarded-Proto should be trusted from proxies", "10.0.0.1:1234",
+			false, map[string][]string{"X-Forwarded-Proto": {"https"}}, true},
+		{"Forwarded should take precedence", "10.0.0.1:1234", false,
+			map[string][]string{"Forwarded": {"proto=http"},
+				"X-Forwarded-Proto": {"https"}}, false},
+		{"The last value should be used", "10.0.0.1:1234", false,
+			map[string][]string{"X-Forwarded-Proto": {"http", "https, http"}},
+			false},
+		{"Headers should be ignored from other peers", "192.0.2.1:1234",
+			false, map[string][]string{"X-Forwarded-Proto": {"https"}}, false},
+		{"IPv4-mapped peers should be matched", "[::ffff:10.0.0.1]:1234",
+			false, map[string][]string{"X-Forwarded-Proto": {"https"}}, true},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "http://example.com/", nil)
+		r.RemoteAddr = test.remoteAddr
+		if test.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		for name, values := range test.header {
+			r.Header[name] = values
+		}
+		if got := IsSecureRequest(r, trusted); got != test.expected {
+			t.Errorf("%s: expected %t, got: %t", test.name, test.expected, got)
+		}
+	}
+}
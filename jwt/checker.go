@@ -0,0 +1,103 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Errors.
+var (
+	ErrProbeMismatch = errors.New("probe token claims do not match")
+)
+
+// probeDat is the dat claim of the probe tokens signed by Checker.
+const probeDat = "webapp-health-probe"
+
+// probeExpiry is how long probe tokens are valid for.
+const probeExpiry = time.Minute
+
+// Checker checks that a Manager can sign tokens and parse them back, so that
+// broken keys are detected before real tokens are issued. It can be used as a
+// readiness check, e.g. with webapp.Server.AddReadinessCheck.
+type Checker struct {
+	manager Manager
+}
+
+// NewChecker creates a checker for the given manager.
+func NewChecker(manager Manager) *Checker {
+	return &Checker{manager: manager}
+}
+
+// Check signs a short-lived probe token and parses it back. An error is
+// returned if either step fails, if the parsed claims differ from the signed
+// ones, or if ctx is done first.
+//
+// If ctx is done first, the channels of the manager are drained in the
+// background, so that the goroutines of the manager can finish.
+func (c *Checker) Check(ctx context.Context) error {
+	claims := NewClaims().WithDat(probeDat).
+		WithExpiry(time.Now().Add(probeExpiry))
+
+	tokenCh, errCh := c.manager.SignCustom(claims)
+	var token string
+	select {
+	case <-ctx.Done():
+		go drain(tokenCh, errCh)
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil {
+			return err
+		}
+		token = <-tokenCh
+	case token = <-tokenCh:
+	}
+
+	parsedCh, errCh := c.manager.ParseCustom(token)
+	select {
+	case <-ctx.Done():
+		go drain(parsedCh, errCh)
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil {
+			return err
+		}
+		return ErrProbeMismatch
+	case parsed := <-parsedCh:
+		if parsed == nil || parsed.Dat != probeDat {
+			return ErrProbeMismatch
+		}
+	}
+	return nil
+}
+
+// drain receives from the channels returned by a Manager until both are
+// closed.
+func drain[T any](resultCh <-chan T, errCh <-chan error) {
+	for resultCh != nil || errCh != nil {
+		select {
+		case _, ok := <-resultCh:
+			if !ok {
+				resultCh = nil
+			}
+		case _, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			}
+		}
+	}
+}
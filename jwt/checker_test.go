@@ -0,0 +1,111 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt_test
+
+import (
+	"context"
+	"crypto/rsa"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qqiao/webapp/v2/jwt"
+)
+
+func TestCheckerBrokenKeys(t *testing.T) {
+	checker := jwt.NewChecker(jwt.NewPS512Manager(&rsa.PublicKey{},
+		&rsa.PrivateKey{}))
+	if err := checker.Check(context.Background()); err == nil {
+		t.Error("Check should fail with broken keys")
+	}
+}
+
+// slowManager delays the results of the wrapped manager, and keeps track of
+// the goroutines delivering them.
+type slowManager struct {
+	jwt.Manager
+
+	signDelay  time.Duration
+	parseDelay time.Duration
+	wg         sync.WaitGroup
+}
+
+func (m *slowManager) SignCustom(claims *jwt.Claims) (<-chan string,
+	<-chan error) {
+	return delay(&m.wg, m.signDelay, func() (<-chan string, <-chan error) {
+		return m.Manager.SignCustom(claims)
+	})
+}
+
+func (m *slowManager) ParseCustom(token string) (<-chan *jwt.Claims,
+	<-chan error) {
+	return delay(&m.wg, m.parseDelay, func() (<-chan *jwt.Claims,
+		<-chan error) {
+		return m.Manager.ParseCustom(token)
+	})
+}
+
+// delay forwards the results of f after d on unbuffered channels.
+func delay[T any](wg *sync.WaitGroup, d time.Duration,
+	f func() (<-chan T, <-chan error)) (<-chan T, <-chan error) {
+	resultCh := make(chan T)
+	errCh := make(chan error)
+
+	wg.Go(func() {
+		defer close(resultCh)
+		defer close(errCh)
+
+		time.Sleep(d)
+		results, errs := f()
+		select {
+		case err := <-errs:
+			errCh <- err
+		case result := <-results:
+			resultCh <- result
+		}
+	})
+
+	return resultCh, errCh
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	hmac, _ := jwt.NewHMACManager("HS256", hmacSecret)
+
+	tests := map[string]*slowManager{
+		"Sign":  {Manager: hmac, signDelay: 100 * time.Millisecond},
+		"Parse": {Manager: hmac, parseDelay: 100 * time.Millisecond},
+	}
+
+	for name, manager := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(),
+			20*time.Millisecond)
+		err := jwt.NewChecker(manager).Check(ctx)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Errorf("%s: expected DeadlineExceeded, got: %v", name, err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			manager.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("%s: manager goroutines leaked after the timeout", name)
+		}
+	}
+}
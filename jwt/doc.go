@@ -16,8 +16,25 @@
 
 Package jwt contains functions related to JWT signing and validation.
 
-Currently, only PS512 algorithm is supported, more methods will be added in
-future releases.
+Tokens can be signed with the RS256, RS384, RS512, PS256, PS384 and PS512
+algorithms using RSAManager, with the ES256, ES384 and ES512 algorithms using
+ECDSAManager, with the EdDSA algorithm using EdDSAManager, or with a shared
+secret using the HS256, HS384 and HS512 algorithms using HMACManager. ECDSA
+and EdDSA signatures are considerably shorter than RSA ones, which helps when
+tokens are sent in cookies or headers.
+
+KeyRingManager combines several of the above managers, identified by the
+kid header, so that keys can be rotated without invalidating all outstanding
+tokens at once.
+
+The public keys of a manager can be published as a JSON Web Key Set with
+NewJWKSHandler, so that other services can verify its tokens.
+
+All managers cache the tokens they have validated, so that validating the
+same token again only checks whether it has expired. Expired tokens are swept
+from the caches periodically.
+
+PS512Manager predates RSAManager and is kept for compatibility.
 
 */
 package jwt
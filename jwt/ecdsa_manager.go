@@ -0,0 +1,92 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ecdsaAlgs maps the supported curves to the algorithms that use them, as
+// defined by RFC 7518 section 3.4.
+var ecdsaAlgs = map[elliptic.Curve]string{
+	elliptic.P256(): "ES256",
+	elliptic.P384(): "ES384",
+	elliptic.P521(): "ES512",
+}
+
+// ECDSAManager is responsible for creating and validating JWT tokens using
+// one of the ES256, ES384 and ES512 algorithms.
+//
+// ECDSA signatures are much shorter than RSA ones, which keeps tokens small
+// enough to be comfortably sent in cookies and headers.
+type ECDSAManager struct {
+	signingKey *ecdsa.PrivateKey
+	parseKey   *ecdsa.PublicKey
+
+	signingMethod jwt.SigningMethod
+
+	validTokens tokenCache
+}
+
+// NewECDSAManager creates a new JWT client that signs and validates JWT tokens
+// with the given key. The algorithm is determined by the curve of the key:
+// ES256 for P-256, ES384 for P-384 and ES512 for P-521.
+//
+// ErrUnsupportedKey is returned if the key or its curve is nil, or if it uses
+// any other curve.
+func NewECDSAManager(privateKey *ecdsa.PrivateKey) (*ECDSAManager, error) {
+	if privateKey == nil || privateKey.Curve == nil {
+		return nil, fmt.Errorf("%w: nil ECDSA key", ErrUnsupportedKey)
+	}
+	alg, ok := ecdsaAlgs[privateKey.Curve]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported curve %s", ErrUnsupportedKey,
+			privateKey.Curve.Params().Name)
+	}
+
+	return &ECDSAManager{
+		signingKey: privateKey,
+		parseKey:   &privateKey.PublicKey,
+
+		signingMethod: jwt.GetSigningMethod(alg),
+	}, nil
+}
+
+// Alg returns the signing algorithm supported by the current manager instance.
+func (m *ECDSAManager) Alg() string {
+	return m.signingMethod.Alg()
+}
+
+// ParseCustom parses a JWT token with the claims and returns the claims of
+// the token.
+func (m *ECDSAManager) ParseCustom(token string) (<-chan *Claims,
+	<-chan error) {
+	return parseCustom(&m.validTokens, token, pinnedKey(m.Alg(), m.parseKey))
+}
+
+// SignCustom signs the JWT token with the given claims.
+func (m *ECDSAManager) SignCustom(claims *Claims) (<-chan string,
+	<-chan error) {
+	return signCustom(m.signingMethod, m.signingKey, "", claims)
+}
+
+// keys returns the signing method and keys of the manager.
+func (m *ECDSAManager) keys() (jwt.SigningMethod, any, any) {
+	return m.signingMethod, m.signingKey, m.parseKey
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// EdDSAManager is responsible for creating and validating JWT tokens using
+// the EdDSA algorithm with Ed25519 keys, as defined by RFC 8037.
+//
+// Ed25519 signatures are only 64 bytes long and are deterministic, so signing
+// the same claims twice results in the same token.
+type EdDSAManager struct {
+	signingKey ed25519.PrivateKey
+	parseKey   ed25519.PublicKey
+
+	signingMethod jwt.SigningMethod
+
+	validTokens tokenCache
+}
+
+// NewEdDSAManager creates a new JWT client that signs and validates JWT tokens
+// with the given key using the EdDSA algorithm.
+//
+// ErrUnsupportedKey is returned if the key is not a valid Ed25519 private
+// key.
+func NewEdDSAManager(privateKey ed25519.PrivateKey) (*EdDSAManager, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: Ed25519 key must be %d bytes, got %d",
+			ErrUnsupportedKey, ed25519.PrivateKeySize, len(privateKey))
+	}
+
+	return &EdDSAManager{
+		signingKey: privateKey,
+		parseKey:   privateKey.Public().(ed25519.PublicKey),
+
+		signingMethod: jwt.SigningMethodEdDSA,
+	}, nil
+}
+
+// Alg returns the signing algorithm supported by the current manager instance.
+func (m *EdDSAManager) Alg() string {
+	return m.signingMethod.Alg()
+}
+
+// ParseCustom parses a JWT token with the claims and returns the claims of
+// the token.
+func (m *EdDSAManager) ParseCustom(token string) (<-chan *Claims,
+	<-chan error) {
+	return parseCustom(&m.validTokens, token, pinnedKey(m.Alg(), m.parseKey))
+}
+
+// SignCustom signs the JWT token with the given claims.
+func (m *EdDSAManager) SignCustom(claims *Claims) (<-chan string,
+	<-chan error) {
+	return signCustom(m.signingMethod, m.signingKey, "", claims)
+}
+
+// keys returns the signing method and keys of the manager.
+func (m *EdDSAManager) keys() (jwt.SigningMethod, any, any) {
+	return m.signingMethod, m.signingKey, m.parseKey
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// hmacKeySizes maps the supported HMAC algorithms to their minimum key sizes
+// in bytes. As recommended by RFC 7518 section 3.2, a key must be at least as
+// long as the output of the hash function.
+var hmacKeySizes = map[string]int{
+	"HS256": 32,
+	"HS384": 48,
+	"HS512": 64,
+}
+
+// HMACManager is responsible for creating and validating JWT tokens using a
+// shared secret with one of the HS256, HS384 and HS512 algorithms.
+//
+// As anyone with the secret can also sign tokens, HMACManager is best suited
+// for tokens exchanged between trusted services.
+type HMACManager struct {
+	secret []byte
+
+	signingMethod jwt.SigningMethod
+
+	validTokens tokenCache
+}
+
+// NewHMACManager creates a new JWT client that signs and validates JWT tokens
+// with the given secret using alg, which must be one of HS256, HS384 and
+// HS512.
+//
+// ErrUnsupportedAlgorithm is returned for any other algorithm, and
+// ErrKeyTooShort is returned if the secret is shorter than the output of the
+// hash function of alg, i.e. 32, 48 and 64 bytes respectively.
+func NewHMACManager(alg string, secret []byte) (*HMACManager, error) {
+	size, ok := hmacKeySizes[alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+	if len(secret) < size {
+		return nil, fmt.Errorf("%w: %s requires at least %d bytes, got %d",
+			ErrKeyTooShort, alg, size, len(secret))
+	}
+
+	return &HMACManager{
+		secret: append([]byte(nil), secret...),
+
+		signingMethod: jwt.GetSigningMethod(alg),
+	}, nil
+}
+
+// Alg returns the signing algorithm supported by the current manager instance.
+func (m *HMACManager) Alg() string {
+	return m.signingMethod.Alg()
+}
+
+// ParseCustom parses a JWT token with the claims and returns the claims of
+// the token.
+func (m *HMACManager) ParseCustom(token string) (<-chan *Claims,
+	<-chan error) {
+	return parseCustom(&m.validTokens, token, pinnedKey(m.Alg(), m.secret))
+}
+
+// SignCustom signs the JWT token with the given claims.
+func (m *HMACManager) SignCustom(claims *Claims) (<-chan string,
+	<-chan error) {
+	return signCustom(m.signingMethod, m.secret, "", claims)
+}
+
+// keys returns the signing method and keys of the manager.
+func (m *HMACManager) keys() (jwt.SigningMethod, any, any) {
+	return m.signingMethod, m.secret, m.secret
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultJWKSMaxAge is how long clients may cache the key set served by
+// NewJWKSHandler by default.
+const DefaultJWKSMaxAge = 15 * time.Minute
+
+// PublicKey is a public key that verifies tokens signed by a Manager.
+type PublicKey struct {
+	// ID is the key ID, as written into the kid header of the tokens. It is
+	// empty for managers that sign tokens without a kid header, in which
+	// case the RFC 7638 thumbprint of the key is published as the kid.
+	ID string
+
+	// Alg is the algorithm the key is used with.
+	Alg string
+
+	// Key is one of *rsa.PublicKey, *ecdsa.PublicKey and ed25519.PublicKey.
+	Key crypto.PublicKey
+}
+
+// PublicKeyProvider is implemented by managers whose tokens can be verified
+// with public keys, so that the keys can be published with NewJWKSHandler.
+//
+// RSAManager, PS512Manager, ECDSAManager, EdDSAManager and KeyRingManager
+// implement PublicKeyProvider. HMACManager does not, as its secret must not be
+// published.
+type PublicKeyProvider interface {
+	// PublicKeys returns the public keys that verify the tokens of the
+	// manager.
+	PublicKeys() []PublicKey
+}
+
+// PublicKeys returns the public key of the manager.
+func (m *RSAManager) PublicKeys() []PublicKey {
+	return []PublicKey{{Alg: m.Alg(), Key: m.parseKey}}
+}
+
+// PublicKeys returns the public key of the manager.
+func (m *PS512Manager) PublicKeys() []PublicKey {
+	return m.manager.PublicKeys()
+}
+
+// PublicKeys returns the public key of the manager.
+func (m *ECDSAManager) PublicKeys() []PublicKey {
+	return []PublicKey{{Alg: m.Alg(), Key: m.parseKey}}
+}
+
+// PublicKeys returns the public key of the manager.
+func (m *EdDSAManager) PublicKeys() []PublicKey {
+	return []PublicKey{{Alg: m.Alg(), Key: m.parseKey}}
+}
+
+// PublicKeys returns the public keys of all keys that have not been retired,
+// including the ones that are not active yet, so that they are known to
+// verifiers before they are used. Keys without public keys, such as HMAC
+// keys, are skipped.
+func (m *KeyRingManager) PublicKeys() []PublicKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]PublicKey, 0, len(m.keys))
+	for _, k := range m.keys {
+		provider, ok := k.manager.(PublicKeyProvider)
+		if !ok || k.retired(now) {
+			continue
+		}
+		for _, key := range provider.PublicKeys() {
+			key.ID = k.id
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// jwk is a JSON Web Key as defined by RFC 7517, RFC 7518 section 6 and RFC
+// 8037 section 2.
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// newJWK converts the public key to a JSON Web Key. If the key has no ID, its
+// RFC 7638 thumbprint is used as the kid, so that every published key has a
+// stable kid. Tokens signed with such keys still carry no kid header, so
+// verifiers pick the key by its alg instead.
+func newJWK(key PublicKey) (*jwk, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	k := &jwk{Use: "sig", Alg: key.Alg, Kid: key.ID}
+	switch pub := key.Key.(type) {
+	case *rsa.PublicKey:
+		if pub == nil || pub.N == nil {
+			return nil, fmt.Errorf("%w: nil RSA key", ErrUnsupportedKey)
+		}
+		k.Kty = "RSA"
+		k.N = encode(pub.N.Bytes())
+		k.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if pub == nil {
+			return nil, fmt.Errorf("%w: nil ECDSA key", ErrUnsupportedKey)
+		}
+		point, err := pub.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+		}
+		// The point is encoded as 0x04 || X || Y.
+		size := (len(point) - 1) / 2
+		k.Kty = "EC"
+		k.Crv = pub.Curve.Params().Name
+		k.X = encode(point[1 : 1+size])
+		k.Y = encode(point[1+size:])
+	case ed25519.PublicKey:
+		k.Kty = "OKP"
+		k.Crv = "Ed25519"
+		k.X = encode(pub)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key.Key)
+	}
+
+	if k.Kid == "" {
+		k.Kid = k.thumbprint()
+	}
+	return k, nil
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the key, using SHA-256.
+func (k *jwk) thumbprint() string {
+	// The required members of each key type, in lexicographic order and
+	// without whitespace.
+	var members string
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, k.E, k.Kty, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Crv,
+			k.Kty, k.X, k.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, k.Crv, k.Kty,
+			k.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// JWKSOptions represents the options of the handler created by
+// NewJWKSHandler.
+type JWKSOptions struct {
+	maxAge time.Duration
+}
+
+// NewJWKSOptions creates JWKSOptions with the default max age of
+// DefaultJWKSMaxAge.
+func NewJWKSOptions() *JWKSOptions {
+	return &JWKSOptions{
+		maxAge: DefaultJWKSMaxAge,
+	}
+}
+
+// WithMaxAge sets how long clients may cache the key set. The max age should
+// be shorter than the time between adding a key to a KeyRingManager and the
+// key becoming active, so that verifiers know the key before it is used.
+func (o *JWKSOptions) WithMaxAge(maxAge time.Duration) *JWKSOptions {
+	o.maxAge = maxAge
+	return o
+}
+
+// NewJWKSHandler creates a handler that publishes the public keys of the
+// provider as an RFC 7517 JSON Web Key Set, so that other services can verify
+// the tokens of a manager. It is typically served at
+// /.well-known/jwks.json.
+//
+// The key set is built on every request, so that keys added to or retired
+// from a KeyRingManager are picked up. Responses carry a Cache-Control max
+// age and an ETag, so that clients can cache and revalidate them.
+func NewJWKSHandler(provider PublicKeyProvider,
+	opts *JWKSOptions) http.Handler {
+	cacheControl := "public, max-age=" +
+		strconv.FormatInt(int64(opts.maxAge/time.Second), 10)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		keys := provider.PublicKeys()
+		set := struct {
+			Keys []*jwk `json:"keys"`
+		}{Keys: make([]*jwk, 0, len(keys))}
+		for _, key := range keys {
+			k, err := newJWK(key)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError)
+				return
+			}
+			set.Keys = append(set.Keys, k)
+		}
+
+		body, err := json.Marshal(set)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+			return
+		}
+		sum := sha256.Sum256(body)
+
+		header := w.Header()
+		header.Set("Content-Type", "application/jwk-set+json")
+		header.Set("Cache-Control", cacheControl)
+		header.Set("ETag",
+			`"`+base64.RawURLEncoding.EncodeToString(sum[:16])+`"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	})
+}
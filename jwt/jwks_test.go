@@ -0,0 +1,249 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qqiao/webapp/v2/jwt"
+)
+
+type keySet struct {
+	Keys []map[string]string `json:"keys"`
+}
+
+func serveJWKS(t *testing.T, h http.Handler, method string,
+	headers map[string]string) (*httptest.ResponseRecorder, keySet) {
+	t.Helper()
+
+	r := httptest.NewRequest(method, "/.well-known/jwks.json", nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var set keySet
+	if w.Code == http.StatusOK && method == http.MethodGet {
+		if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+			t.Fatalf("Unable to decode key set: %v", err)
+		}
+	}
+	return w, set
+}
+
+func TestJWKSHandler(t *testing.T) {
+	hmac, _ := jwt.NewHMACManager("HS256", hmacSecret)
+	rsaManager, _ := jwt.NewRSAManager("RS256", rsaPublicKey, rsaPrivateKey)
+	eddsa := newEdDSAManager()
+	manager, err := jwt.NewKeyRingManager(
+		jwt.NewKey("rsa", rsaManager),
+		jwt.NewKey("es512", newECDSAManager("ES512")),
+		jwt.NewKey("ed", eddsa).WithActiveFrom(time.Now().Add(time.Hour)),
+		jwt.NewKey("hmac", hmac),
+		jwt.NewKey("retired", newECDSAManager("ES256")).
+			WithRetireAt(time.Now()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := jwt.NewJWKSHandler(manager, jwt.NewJWKSOptions().
+		WithMaxAge(time.Hour))
+	w, set := serveJWKS(t, h, http.MethodGet, nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got: %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/jwk-set+json" {
+		t.Errorf("Unexpected Content-Type: %s", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=3600" {
+		t.Errorf("Unexpected Cache-Control: %s", got)
+	}
+
+	expected := map[string]map[string]string{
+		"rsa":   {"kty": "RSA", "alg": "RS256", "e": "AQAB"},
+		"es512": {"kty": "EC", "alg": "ES512", "crv": "P-521"},
+		"ed":    {"kty": "OKP", "alg": "EdDSA", "crv": "Ed25519"},
+	}
+	if len(set.Keys) != len(expected) {
+		t.Errorf("Expected %d keys, got: %v", len(expected), set.Keys)
+	}
+	for _, key := range set.Keys {
+		fields, found := expected[key["kid"]]
+		if !found {
+			t.Errorf("Unexpected key: %v", key)
+			continue
+		}
+		fields["use"] = "sig"
+		for name, value := range fields {
+			if key[name] != value {
+				t.Errorf("%s: expected %s to be %q, got: %q", key["kid"], name,
+					value, key[name])
+			}
+		}
+		if _, found := key["d"]; found {
+			t.Errorf("%s: private key must not be published", key["kid"])
+		}
+	}
+
+	for _, key := range set.Keys {
+		switch key["kty"] {
+		case "RSA":
+			n, _ := base64.RawURLEncoding.DecodeString(key["n"])
+			if new(big.Int).SetBytes(n).Cmp(rsaPublicKey.N) != 0 {
+				t.Error("RSA modulus does not match the public key")
+			}
+		case "EC":
+			for _, coordinate := range []string{"x", "y"} {
+				c, _ := base64.RawURLEncoding.DecodeString(key[coordinate])
+				if len(c) != 66 {
+					t.Errorf("P-521 %s should be 66 bytes, got: %d",
+						coordinate, len(c))
+				}
+			}
+		case "OKP":
+			x, _ := base64.RawURLEncoding.DecodeString(key["x"])
+			if !ed25519.PublicKey(x).Equal(eddsa.PublicKeys()[0].Key) {
+				t.Error("Ed25519 key does not match the public key")
+			}
+		}
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+	if w, _ = serveJWKS(t, h, http.MethodGet, map[string]string{
+		"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Errorf("Matching ETag should result in 304, got: %d", w.Code)
+	}
+	if w, _ = serveJWKS(t, h, http.MethodPost, nil); w.Code !=
+		http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got: %d", w.Code)
+	}
+}
+
+type staticKeys []jwt.PublicKey
+
+func (k staticKeys) PublicKeys() []jwt.PublicKey {
+	return k
+}
+
+func TestJWKSHandlerKeyID(t *testing.T) {
+	rsaManager, _ := jwt.NewRSAManager("PS256", rsaPublicKey, rsaPrivateKey)
+	ring, err := jwt.NewKeyRingManager(
+		jwt.NewKey("old", newECDSAManager("ES256")).
+			WithActiveFrom(time.Unix(0, 0)),
+		jwt.NewKey("current", newEdDSAManager()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managers := map[string]interface {
+		jwt.Manager
+		jwt.PublicKeyProvider
+	}{
+		"RSA":      rsaManager,
+		"PS512":    jwt.NewPS512Manager(rsaPublicKey, rsaPrivateKey),
+		"ECDSA":    newECDSAManager("ES384"),
+		"EdDSA":    newEdDSAManager(),
+		"Key ring": ring,
+	}
+
+	for name, manager := range managers {
+		token := sign(t, manager)
+		id := kid(t, token)
+
+		w, set := serveJWKS(t, jwt.NewJWKSHandler(manager,
+			jwt.NewJWKSOptions()), http.MethodGet, nil)
+		if got := w.Header().Get("Cache-Control"); got !=
+			"public, max-age=900" {
+			t.Errorf("%s: unexpected default Cache-Control: %s", name, got)
+		}
+
+		// Tokens without a kid header are verified with the key of their
+		// alg, which must still have a kid.
+		var found map[string]string
+		for _, key := range set.Keys {
+			if key["kid"] == id || id == "" && key["alg"] == manager.Alg() {
+				found = key
+			}
+		}
+		if found == nil {
+			t.Errorf("%s: no key with kid %q in %v", name, id, set.Keys)
+			continue
+		}
+		if found["kid"] == "" {
+			t.Errorf("%s: every key should have a kid, got: %v", name, found)
+		}
+		if found["alg"] != manager.Alg() {
+			t.Errorf("%s: expected alg %s, got: %s", name, manager.Alg(),
+				found["alg"])
+		}
+	}
+}
+
+func TestJWKSHandlerThumbprint(t *testing.T) {
+	// The example key of RFC 7638 section 3.1.
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	h := jwt.NewJWKSHandler(staticKeys{{Alg: "RS256", Key: &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n), E: 65537}}}, jwt.NewJWKSOptions())
+
+	_, set := serveJWKS(t, h, http.MethodGet, nil)
+	if len(set.Keys) != 1 {
+		t.Fatalf("Expected 1 key, got: %v", set.Keys)
+	}
+	if kid := set.Keys[0]["kid"]; kid !=
+		"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("Keys without ID should use their thumbprint, got: %s", kid)
+	}
+}
+
+func TestJWKSHandlerUnsupportedKey(t *testing.T) {
+	h := jwt.NewJWKSHandler(staticKeys{{Alg: "HS256", Key: hmacSecret}},
+		jwt.NewJWKSOptions())
+
+	if w, _ := serveJWKS(t, h, http.MethodGet, nil); w.Code !=
+		http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got: %d", w.Code)
+	}
+}
+
+func ExampleNewJWKSHandler() {
+	manager, err := jwt.NewEdDSAManager(ed25519.NewKeyFromSeed(
+		make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		panic(err)
+	}
+
+	h := jwt.NewJWKSHandler(manager, jwt.NewJWKSOptions())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	fmt.Println(w.Body.String())
+
+	// Output: {"keys":[{"kty":"OKP","use":"sig","alg":"EdDSA","kid":"9ZP03Nu8GrXPAUkbKNxHOKBzxPX83SShgFkRNK-f2lw","crv":"Ed25519","x":"O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik"}]}
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors.
+var (
+	ErrDuplicateKeyID = errors.New("duplicate key ID")
+	ErrKeyNotFound    = errors.New("key not found")
+	ErrKeyRetired     = errors.New("key retired")
+	ErrNoActiveKey    = errors.New("no active key")
+)
+
+// Key is a key of a KeyRingManager. It pairs a key ID with a manager that
+// holds the actual keys, and optionally schedules when the key becomes active
+// and when it is retired.
+type Key struct {
+	id         string
+	manager    Manager
+	activeFrom time.Time
+	retireAt   time.Time
+}
+
+// NewKey creates a key with the given ID, which is written into the kid
+// header of the tokens signed with it. The manager must be created by one of
+// the constructors of this package, e.g. NewECDSAManager or NewRSAManager.
+//
+// A key with an empty ID signs tokens without a kid header, and verifies
+// tokens without one. This allows tokens issued by a single key manager to
+// remain valid after moving it into a key ring.
+func NewKey(id string, manager Manager) *Key {
+	return &Key{
+		id:      id,
+		manager: manager,
+	}
+}
+
+// WithActiveFrom schedules the key to be used for signing from the given
+// time onwards. The key is used for verification right away, so that tokens
+// signed by other instances whose clocks are slightly ahead are accepted.
+func (k *Key) WithActiveFrom(activeFrom time.Time) *Key {
+	k.activeFrom = activeFrom
+	return k
+}
+
+// WithRetireAt schedules the key to be retired at the given time. Once
+// retired, the key is no longer used for either signing or verification, so
+// the retirement time should leave enough room for the tokens signed with it
+// to expire.
+func (k *Key) WithRetireAt(retireAt time.Time) *Key {
+	k.retireAt = retireAt
+	return k
+}
+
+// ID returns the ID of the key.
+func (k *Key) ID() string {
+	return k.id
+}
+
+// active reports whether the key can be used for signing at the given time.
+func (k *Key) active(now time.Time) bool {
+	return !k.activeFrom.After(now) && !k.retired(now)
+}
+
+// retired reports whether the key has been retired at the given time.
+func (k *Key) retired(now time.Time) bool {
+	return !k.retireAt.IsZero() && !k.retireAt.After(now)
+}
+
+// KeyRingManager is responsible for creating and validating JWT tokens using
+// multiple keys, so that keys can be rotated without invalidating all
+// outstanding tokens at once.
+//
+// Tokens are signed with the active key that became active most recently,
+// and the ID of the key is written into the kid header. Tokens are verified
+// with the key identified by their kid header, as long as it has not been
+// retired. A typical rotation adds the new key with an activation time in
+// the near future, and schedules the retirement of the old key for when the
+// last tokens signed with it expire.
+//
+// Like the other managers, KeyRingManager caches already validated tokens.
+type KeyRingManager struct {
+	mu   sync.RWMutex
+	keys []*Key
+
+	validTokens tokenCache
+}
+
+// NewKeyRingManager creates a new JWT client with the given keys. See AddKey
+// for the errors returned.
+func NewKeyRingManager(keys ...*Key) (*KeyRingManager, error) {
+	m := &KeyRingManager{}
+	for _, key := range keys {
+		if err := m.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// AddKey adds the key to the key ring.
+//
+// ErrDuplicateKeyID is returned if a key with the same ID already exists, and
+// ErrUnsupportedKey is returned if the manager of the key is not created by
+// one of the constructors of this package.
+func (m *KeyRingManager) AddKey(key *Key) error {
+	if _, ok := key.manager.(keyedManager); !ok {
+		return fmt.Errorf("%w: %T", ErrUnsupportedKey, key.manager)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range m.keys {
+		if k.id == key.id {
+			return fmt.Errorf("%w: %q", ErrDuplicateKeyID, key.id)
+		}
+	}
+
+	k := *key
+	m.keys = append(m.keys, &k)
+	return nil
+}
+
+// RetireKey schedules the key with the given ID to be retired at the given
+// time. Passing the current time retires the key immediately.
+//
+// ErrKeyNotFound is returned if there is no key with the given ID.
+func (m *KeyRingManager) RetireKey(id string, retireAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, k := range m.keys {
+		if k.id == id {
+			retired := *k
+			retired.retireAt = retireAt
+			m.keys[i] = &retired
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+}
+
+// activeKey returns the key to sign tokens with at the given time, which is
+// the active key that became active most recently. Nil is returned if there
+// are no active keys.
+func (m *KeyRingManager) activeKey(now time.Time) *Key {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var active *Key
+	for _, k := range m.keys {
+		if k.active(now) &&
+			(active == nil || !k.activeFrom.Before(active.activeFrom)) {
+			active = k
+		}
+	}
+	return active
+}
+
+// key returns the key with the given ID, unless it has been retired at the
+// given time.
+func (m *KeyRingManager) key(id string, now time.Time) (*Key, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, k := range m.keys {
+		if k.id != id {
+			continue
+		}
+		if k.retired(now) {
+			return nil, fmt.Errorf("%w: %q", ErrKeyRetired, id)
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+}
+
+// Alg returns the signing algorithm of the current active key. An empty
+// string is returned if there are no active keys.
+func (m *KeyRingManager) Alg() string {
+	if key := m.activeKey(time.Now()); key != nil {
+		return key.manager.Alg()
+	}
+	return ""
+}
+
+// ParseCustom parses a JWT token with the claims and returns the claims of
+// the token.
+//
+// The token is verified with the key identified by its kid header, using the
+// algorithm of that key. ErrKeyNotFound is returned if there is no such key,
+// and ErrKeyRetired is returned if the key has been retired, even if the
+// token has been validated before.
+func (m *KeyRingManager) ParseCustom(token string) (<-chan *Claims,
+	<-chan error) {
+	id, err := keyID(token)
+	if err != nil {
+		return failed[*Claims](err)
+	}
+	key, err := m.key(id, time.Now())
+	if err != nil {
+		return failed[*Claims](err)
+	}
+
+	method, _, parseKey := key.manager.(keyedManager).keys()
+	return parseCustom(&m.validTokens, token, pinnedKey(method.Alg(),
+		parseKey))
+}
+
+// SignCustom signs the JWT token with the given claims using the current
+// active key. ErrNoActiveKey is returned if there are no active keys.
+func (m *KeyRingManager) SignCustom(claims *Claims) (<-chan string,
+	<-chan error) {
+	key := m.activeKey(time.Now())
+	if key == nil {
+		return failed[string](ErrNoActiveKey)
+	}
+
+	method, signingKey, _ := key.manager.(keyedManager).keys()
+	return signCustom(method, signingKey, key.id, claims)
+}
+
+// keyID returns the kid header of the token without verifying it.
+func keyID(token string) (string, error) {
+	segment, _, _ := strings.Cut(token, ".")
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", jwt.ErrTokenMalformed, err)
+	}
+
+	var header struct {
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("%w: %v", jwt.ErrTokenMalformed, err)
+	}
+	return header.Kid, nil
+}
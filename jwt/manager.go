@@ -14,6 +14,24 @@
 
 package jwt
 
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors.
+var (
+	ErrKeyTooShort          = errors.New("key too short")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrUnsupportedKey       = errors.New("unsupported key")
+
+	// ErrTokenExpired is matched by the errors of parsing expired tokens,
+	// whether or not they have been validated before. It is the same error
+	// as the one of github.com/golang-jwt/jwt/v5.
+	ErrTokenExpired = jwt.ErrTokenExpired
+)
+
 // Manager is responsible for all the JWT token related operations.
 type Manager interface {
 	// Alg returns the signing algorithm supported by the current manager
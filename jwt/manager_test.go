@@ -15,6 +15,7 @@
 package jwt_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -103,6 +104,14 @@ func TestManagers(t *testing.T) {
 				testRepeatableParseCustoms(t, tc.manager, tc.token, tc.expected)
 			})
 
+		t.Run(fmt.Sprintf("%s should pass the health check", alg),
+			func(t *testing.T) {
+				if err := jwt.NewChecker(tc.manager).Check(
+					context.Background()); err != nil {
+					t.Errorf("Check should succeed: %v", err)
+				}
+			})
+
 		for _, dat := range tc.dat {
 			t.Run(fmt.Sprintf("%s should sign correctly", alg),
 				func(t *testing.T) {
@@ -0,0 +1,107 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSAKeySize is the minimum size in bits of the keys accepted by
+// NewRSAManager, as required by RFC 7518 section 3.3.
+const MinRSAKeySize = 2048
+
+// rsaAlgs lists the supported RSA algorithms.
+var rsaAlgs = map[string]bool{
+	"RS256": true,
+	"RS384": true,
+	"RS512": true,
+	"PS256": true,
+	"PS384": true,
+	"PS512": true,
+}
+
+// RSAManager is responsible for creating and validating JWT tokens using one
+// of the RS256, RS384, RS512, PS256, PS384 and PS512 algorithms.
+//
+// The RSASSA-PSS algorithms (PS*) should be preferred. The RSASSA-PKCS1-v1_5
+// algorithms (RS*) are supported for interoperability with verifiers that do
+// not support RSASSA-PSS.
+type RSAManager struct {
+	signingKey *rsa.PrivateKey
+	parseKey   *rsa.PublicKey
+
+	signingMethod jwt.SigningMethod
+
+	validTokens tokenCache
+}
+
+// NewRSAManager creates a new JWT client that signs JWT tokens with the
+// private key and validates them with the public key using alg.
+//
+// ErrUnsupportedAlgorithm is returned if alg is not an RSA algorithm,
+// ErrUnsupportedKey is returned if either key is nil, and ErrKeyTooShort is
+// returned if the key is shorter than MinRSAKeySize.
+func NewRSAManager(alg string, publicKey *rsa.PublicKey,
+	privateKey *rsa.PrivateKey) (*RSAManager, error) {
+	if !rsaAlgs[alg] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+	if publicKey == nil || publicKey.N == nil || privateKey == nil {
+		return nil, fmt.Errorf("%w: nil RSA key", ErrUnsupportedKey)
+	}
+	if size := publicKey.N.BitLen(); size < MinRSAKeySize {
+		return nil, fmt.Errorf("%w: RSA keys must be at least %d bits, got %d",
+			ErrKeyTooShort, MinRSAKeySize, size)
+	}
+
+	return newRSAManager(alg, publicKey, privateKey), nil
+}
+
+// newRSAManager creates an RSAManager without validating its arguments.
+func newRSAManager(alg string, publicKey *rsa.PublicKey,
+	privateKey *rsa.PrivateKey) *RSAManager {
+	return &RSAManager{
+		parseKey:   publicKey,
+		signingKey: privateKey,
+
+		signingMethod: jwt.GetSigningMethod(alg),
+	}
+}
+
+// Alg returns the signing algorithm supported by the current manager instance.
+func (m *RSAManager) Alg() string {
+	return m.signingMethod.Alg()
+}
+
+// ParseCustom parses a JWT token with the claims and returns the claims of
+// the token.
+func (m *RSAManager) ParseCustom(token string) (<-chan *Claims,
+	<-chan error) {
+	return parseCustom(&m.validTokens, token, pinnedKey(m.Alg(), m.parseKey))
+}
+
+// SignCustom signs the JWT token with the given claims.
+func (m *RSAManager) SignCustom(claims *Claims) (<-chan string,
+	<-chan error) {
+	return signCustom(m.signingMethod, m.signingKey, "", claims)
+}
+
+// keys returns the signing method and keys of the manager.
+func (m *RSAManager) keys() (jwt.SigningMethod, any, any) {
+	return m.signingMethod, m.signingKey, m.parseKey
+}
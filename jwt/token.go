@@ -0,0 +1,182 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyedManager is implemented by the managers of this package. It gives
+// access to the signing method and keys of a manager, so that they can be
+// used by KeyRingManager.
+type keyedManager interface {
+	Manager
+
+	keys() (method jwt.SigningMethod, signingKey any, parseKey any)
+}
+
+// sweepInterval is the minimum interval between two sweeps of a token cache.
+const sweepInterval = time.Minute
+
+// tokenCache caches already validated tokens along with their claims, so that
+// validating a token again only checks its expiry instead of verifying its
+// signature. Each manager has a cache of its own, as a token validated by one
+// manager must not be trusted by another.
+type tokenCache struct {
+	tokens    sync.Map
+	lastSweep atomic.Int64
+}
+
+// load returns the cached claims of the token.
+func (c *tokenCache) load(token string) (*Claims, bool) {
+	claims, has := c.tokens.Load(token)
+	if !has {
+		return nil, false
+	}
+	return claims.(*Claims), true
+}
+
+// store caches the claims of a validated token, and sweeps expired tokens
+// from the cache at most once per sweepInterval.
+func (c *tokenCache) store(token string, claims *Claims) {
+	c.tokens.Store(token, claims)
+
+	now := time.Now()
+	last := c.lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < sweepInterval ||
+		!c.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	c.tokens.Range(func(token, claims any) bool {
+		if expired(claims.(*Claims), now) {
+			c.tokens.Delete(token)
+		}
+		return true
+	})
+}
+
+// expired reports whether the claims have expired at the given time.
+func expired(claims *Claims, now time.Time) bool {
+	return claims.RegisteredClaims != nil && claims.ExpiresAt != nil &&
+		claims.ExpiresAt.Before(now)
+}
+
+// pinnedKey returns a key function that only accepts tokens signed with the
+// given algorithm, so that a token cannot pick the algorithm it is verified
+// with, e.g. HS256 with an RSA public key as the secret.
+func pinnedKey(alg string, key any) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		if token.Method.Alg() != alg {
+			return nil, fmt.Errorf("unexpected algorithm: %s",
+				token.Header["alg"])
+		}
+		return key, nil
+	}
+}
+
+// parseCustom parses and validates the token with the key returned by
+// keyFunc. Validated tokens are cached, and cached tokens are only checked
+// for expiry.
+func parseCustom(cache *tokenCache, token string,
+	keyFunc jwt.Keyfunc) (<-chan *Claims, <-chan error) {
+	resultCh := make(chan *Claims)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(resultCh)
+		defer close(errCh)
+
+		// First let check if we have it in the valid tokens cache
+		if cl, has := cache.load(token); has {
+			if expired(cl, time.Now()) {
+				errCh <- fmt.Errorf("%w: %w", jwt.ErrTokenInvalidClaims,
+					ErrTokenExpired)
+				return
+			}
+
+			resultCh <- cl
+			return
+		}
+
+		// If we don't, we parse the token
+		t, err := jwt.ParseWithClaims(token, &Claims{}, keyFunc)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		claims, ok := t.Claims.(*Claims)
+		if !ok {
+			errCh <- errors.New("unable to parse claims")
+			return
+		}
+
+		// After parsing the token, we save it to the valid tokens cache
+		cache.store(token, claims)
+
+		resultCh <- claims
+	}()
+
+	return resultCh, errCh
+}
+
+// failed returns channels that deliver err, for when an operation fails
+// before it can be started.
+func failed[T any](err error) (<-chan T, <-chan error) {
+	resultCh := make(chan T)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(resultCh)
+		defer close(errCh)
+
+		errCh <- err
+	}()
+
+	return resultCh, errCh
+}
+
+// signCustom signs a token with the given claims. If kid is not empty, it is
+// written into the kid header of the token.
+func signCustom(method jwt.SigningMethod, key any, kid string,
+	claims *Claims) (<-chan string, <-chan error) {
+	tokenCh := make(chan string)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+		defer close(tokenCh)
+
+		t := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			t.Header["kid"] = kid
+		}
+		token, err := t.SignedString(key)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		tokenCh <- token
+	}()
+
+	return tokenCh, errCh
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseCustomCachedExpiredToken(t *testing.T) {
+	// A token that was valid when it was cached, and has expired since but
+	// has not been swept yet.
+	var cache tokenCache
+	cache.tokens.Store("token",
+		NewClaims().WithExpiry(time.Now().Add(-time.Minute)))
+
+	claimsCh, errCh := parseCustom(&cache, "token",
+		func(*jwt.Token) (any, error) {
+			t.Error("Cached tokens should not be verified again")
+			return nil, errors.New("unexpected verification")
+		})
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrTokenExpired) ||
+			!errors.Is(err, jwt.ErrTokenInvalidClaims) {
+			t.Errorf("Expected an expired token error, got: %v", err)
+		}
+	case <-claimsCh:
+		t.Error("Expired token should not be valid")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LanguageRange represents a single entry of an Accept-Language header.
+type LanguageRange struct {
+	// Tag is the language range as sent by the client, e.g. zh-TW or *.
+	Tag string
+
+	// Quality is the relative weight of the range, between 0 and 1.
+	Quality float64
+}
+
+// ParseAcceptLanguage parses the value of an Accept-Language header.
+//
+// The returned ranges are sorted by descending quality, ranges with the same
+// quality keep the order in which they appear in the header. Malformed
+// entries are skipped.
+func ParseAcceptLanguage(header string) []LanguageRange {
+	values := parseQualityList(header)
+	ranges := make([]LanguageRange, 0, len(values))
+	for _, v := range values {
+		ranges = append(ranges, LanguageRange{
+			Tag:     v.value,
+			Quality: v.quality,
+		})
+	}
+	return ranges
+}
+
+// DetermineLocaleFromHeader finds the best locale from a list of available
+// locales for the given Accept-Language header value. It applies the
+// following logic:
+//  1. Language ranges are tried in descending order of quality, each range is
+//     matched against the available locales with the same rules as
+//     DetermineLocale.
+//  2. A * range matches the first available locale that has not been
+//     excluded.
+//  3. A range with a quality of 0 excludes all the available locales that it
+//     matches, even if a less specific range would otherwise match them.
+//  4. If nothing matches, an empty string is returned.
+func DetermineLocaleFromHeader(header string, available []string) string {
+	ranges := ParseAcceptLanguage(header)
+
+	candidates := make([]string, 0, len(available))
+	for _, locale := range available {
+		if !excludedLocale(locale, ranges) {
+			candidates = append(candidates, locale)
+		}
+	}
+
+	for _, r := range ranges {
+		if r.Quality == 0 {
+			// Ranges are sorted, nothing after this is acceptable.
+			break
+		}
+		if r.Tag == "*" {
+			if len(candidates) > 0 {
+				return candidates[0]
+			}
+			continue
+		}
+		if locale := DetermineLocale(r.Tag, candidates); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// DetermineLocaleFromRequest finds the best locale from a list of available
+// locales for the Accept-Language headers of the given request.
+//
+// Please refer to DetermineLocaleFromHeader for the matching rules.
+func DetermineLocaleFromRequest(r *http.Request, available []string) string {
+	return DetermineLocaleFromHeader(
+		strings.Join(r.Header.Values("Accept-Language"), ","), available)
+}
+
+// excludedLocale reports whether the locale has been explicitly marked as not
+// acceptable by a range with a quality of 0.
+func excludedLocale(locale string, ranges []LanguageRange) bool {
+	for _, r := range ranges {
+		if r.Quality == 0 && r.Tag != "*" && equalOrLessSpecific(r.Tag, locale) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LocaleSource represents a part of the request the locale can be read from.
+type LocaleSource int
+
+// Possible locale sources.
+const (
+	// LocaleSourcePath reads the locale from the first segment of the URL
+	// path, e.g. /zh-TW/about. The segment must be one of the available
+	// locales.
+	LocaleSourcePath LocaleSource = iota
+
+	// LocaleSourceQuery reads the locale from a query parameter.
+	LocaleSourceQuery
+
+	// LocaleSourceCookie reads the locale from a cookie.
+	LocaleSourceCookie
+
+	// LocaleSourceHeader reads the locale from the Accept-Language header.
+	LocaleSourceHeader
+)
+
+// Default names used by LocaleOptions.
+const (
+	DefaultLocaleQueryParameter = "lang"
+	DefaultLocaleCookieName     = "lang"
+)
+
+type localeContextKey struct{}
+
+// LocaleOptions controls how the locale of a request is negotiated by the
+// handler created with NewLocaleHandler.
+type LocaleOptions struct {
+	available      []string
+	sources        []LocaleSource
+	queryParameter string
+	cookieName     string
+}
+
+// NewLocaleOptions creates locale negotiation options for the given available
+// locales. The first available locale is used as the default.
+//
+// By default, the sources are checked in the following order: URL path
+// prefix, query parameter, cookie and Accept-Language header.
+func NewLocaleOptions(available ...string) *LocaleOptions {
+	return &LocaleOptions{
+		available: available,
+		sources: []LocaleSource{
+			LocaleSourcePath,
+			LocaleSourceQuery,
+			LocaleSourceCookie,
+			LocaleSourceHeader,
+		},
+		queryParameter: DefaultLocaleQueryParameter,
+		cookieName:     DefaultLocaleCookieName,
+	}
+}
+
+// WithSources sets the sources to check and the order in which they are
+// checked. Sources not listed are ignored.
+func (o *LocaleOptions) WithSources(sources ...LocaleSource) *LocaleOptions {
+	o.sources = sources
+	return o
+}
+
+// WithQueryParameter sets the name of the query parameter the locale is read
+// from.
+func (o *LocaleOptions) WithQueryParameter(name string) *LocaleOptions {
+	o.queryParameter = name
+	return o
+}
+
+// WithCookieName sets the name of the cookie the locale is read from.
+func (o *LocaleOptions) WithCookieName(name string) *LocaleOptions {
+	o.cookieName = name
+	return o
+}
+
+// NewLocaleHandler takes a normal HTTP handler and adds locale negotiation to
+// it.
+//
+// The locale is resolved once per request by checking the configured sources
+// in order. The first source that yields one of the available locales, as
+// determined by DetermineLocale, wins. If none does, the result of
+// DetermineLocaleWithDefault is used.
+//
+// The negotiated locale is stored in the request context and can be
+// retrieved with LocaleFromContext. The Content-Language header is set to the
+// negotiated locale and Accept-Language is added to the Vary header.
+//
+// If the locale is read from the URL path, the locale segment is removed from
+// the path before the request is passed on, so that /zh-TW/about is handled
+// as /about.
+func NewLocaleHandler(h http.Handler, opts *LocaleOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, r := opts.negotiate(r)
+
+		addVary(w.Header(), "Accept-Language")
+		if locale != "" {
+			w.Header().Set("Content-Language", locale)
+		}
+
+		h.ServeHTTP(w, r.WithContext(ContextWithLocale(r.Context(), locale)))
+	})
+}
+
+// LocaleFromContext returns the locale stored in the context by the handler
+// created with NewLocaleHandler. An empty string is returned if there is no
+// locale in the context.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeContextKey{}).(string)
+	return locale
+}
+
+// ContextWithLocale returns a copy of the context with the given locale
+// stored in it.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// negotiate determines the locale for the request. If the locale comes from
+// the URL path, the returned request has the locale segment removed.
+func (o *LocaleOptions) negotiate(r *http.Request) (string, *http.Request) {
+	for _, source := range o.sources {
+		switch source {
+		case LocaleSourcePath:
+			segment, rest := splitLocaleSegment(r.URL.Path)
+			if locale := o.pathLocale(segment); locale != "" {
+				return locale, stripLocaleSegment(r, rest)
+			}
+		case LocaleSourceQuery:
+			value := r.URL.Query().Get(o.queryParameter)
+			if locale := DetermineLocale(value, o.available); locale != "" {
+				return locale, r
+			}
+		case LocaleSourceCookie:
+			if c, err := r.Cookie(o.cookieName); err == nil {
+				if locale := DetermineLocale(c.Value, o.available); locale != "" {
+					return locale, r
+				}
+			}
+		case LocaleSourceHeader:
+			if locale := DetermineLocaleFromRequest(r, o.available); locale != "" {
+				return locale, r
+			}
+		}
+	}
+	return DetermineLocaleWithDefault("", o.available), r
+}
+
+// pathLocale returns the available locale the given path segment is, or an
+// empty string if there is none. Unlike the other sources, the segment has to
+// match exactly, apart from case, so that paths such as /en-route are not
+// mistaken for locale prefixes.
+func (o *LocaleOptions) pathLocale(segment string) string {
+	for _, locale := range o.available {
+		if strings.EqualFold(segment, locale) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// splitLocaleSegment splits the first segment off the given path.
+func splitLocaleSegment(path string) (string, string) {
+	path = strings.TrimPrefix(path, "/")
+	segment, rest, _ := strings.Cut(path, "/")
+	return segment, "/" + rest
+}
+
+// stripLocaleSegment returns a shallow copy of the request with the URL path
+// replaced by path.
+func stripLocaleSegment(r *http.Request, path string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+	return r2
+}
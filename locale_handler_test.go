@@ -0,0 +1,125 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveLocale(opts *LocaleOptions, r *http.Request) (string, string,
+	*httptest.ResponseRecorder) {
+	var locale, path string
+	h := NewLocaleHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		locale = LocaleFromContext(r.Context())
+		path = r.URL.Path
+	}), opts)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return locale, path, w
+}
+
+func TestLocaleHandler(t *testing.T) {
+	opts := NewLocaleOptions(available...)
+
+	t.Run("Path prefix should be used and stripped", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/zh-tw/about?lang=en", nil)
+		locale, path, w := serveLocale(opts, r)
+		if locale != "zh-TW" {
+			t.Errorf("Expected locale zh-TW, got: %q", locale)
+		}
+		if path != "/about" {
+			t.Errorf("Expected path /about, got: %q", path)
+		}
+		if w.Header().Get("Content-Language") != "zh-TW" {
+			t.Errorf("Expected Content-Language zh-TW, got: %q",
+				w.Header().Get("Content-Language"))
+		}
+		if w.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("Expected Vary Accept-Language, got: %q",
+				w.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("Unknown path segments should be left alone", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/about?lang=zh", nil)
+		locale, path, _ := serveLocale(opts, r)
+		if locale != "zh" {
+			t.Errorf("Expected locale zh, got: %q", locale)
+		}
+		if path != "/about" {
+			t.Errorf("Expected path /about, got: %q", path)
+		}
+	})
+
+	t.Run("Segments starting with a locale should be left alone",
+		func(t *testing.T) {
+			for _, target := range []string{"/en-route/x", "/zh-module.js",
+				"/en-us-docs"} {
+				r := httptest.NewRequest("GET", target+"?lang=zh", nil)
+				locale, path, _ := serveLocale(opts, r)
+				if locale != "zh" {
+					t.Errorf("%s: expected locale zh, got: %q", target, locale)
+				}
+				if path != target {
+					t.Errorf("%s: expected path to be unchanged, got: %q",
+						target, path)
+				}
+			}
+		})
+
+	t.Run("Cookie should be preferred over header", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(&http.Cookie{Name: DefaultLocaleCookieName, Value: "en-US"})
+		r.Header.Set("Accept-Language", "zh")
+		if locale, _, _ := serveLocale(opts, r); locale != "en-us" {
+			t.Errorf("Expected locale en-us, got: %q", locale)
+		}
+	})
+
+	t.Run("Header should be used when nothing else matches", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/?lang=es", nil)
+		r.Header.Set("Accept-Language", "es, zh;q=0.5")
+		if locale, _, _ := serveLocale(opts, r); locale != "zh" {
+			t.Errorf("Expected locale zh, got: %q", locale)
+		}
+	})
+
+	t.Run("Default should be used as a last resort", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Language", "es")
+		if locale, _, _ := serveLocale(opts, r); locale != available[0] {
+			t.Errorf("Expected locale %q, got: %q", available[0], locale)
+		}
+	})
+
+	t.Run("Order of sources should be configurable", func(t *testing.T) {
+		opts := NewLocaleOptions(available...).
+			WithSources(LocaleSourceHeader, LocaleSourceQuery).
+			WithQueryParameter("hl")
+		r := httptest.NewRequest("GET", "/zh/about?hl=zh-TW", nil)
+		r.Header.Set("Accept-Language", "es")
+		locale, path, _ := serveLocale(opts, r)
+		if locale != "zh-TW" {
+			t.Errorf("Expected locale zh-TW, got: %q", locale)
+		}
+		if path != "/zh/about" {
+			t.Errorf("Expected path /zh/about, got: %q", path)
+		}
+	})
+}
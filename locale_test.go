@@ -0,0 +1,79 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	ranges := ParseAcceptLanguage("fr;q=0.5, zh-TW, en;q=0.8, de;q=bad, , *;q=0.1")
+	expected := []LanguageRange{
+		{Tag: "zh-TW", Quality: 1},
+		{Tag: "en", Quality: 0.8},
+		{Tag: "fr", Quality: 0.5},
+		{Tag: "*", Quality: 0.1},
+	}
+
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected %d ranges, got: %v", len(expected), ranges)
+	}
+	for i, r := range ranges {
+		if r != expected[i] {
+			t.Errorf("Expected range %d to be %v, got: %v", i, expected[i], r)
+		}
+	}
+
+	if len(ParseAcceptLanguage("")) != 0 {
+		t.Error("ParseAcceptLanguage(\"\") should return no ranges")
+	}
+}
+
+func TestDetermineLocaleFromHeader(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"es", ""},
+		{"zh-tw", "zh-TW"},
+		{"zh-HK", "zh"},
+		{"es, en-US;q=0.9, zh;q=0.8", "en-us"},
+		{"zh;q=0.5, en;q=0.9", "en"},
+		{"es, *;q=0.1", "en"},
+		{"en;q=0, *", "zh"},
+		{"en-us;q=0, en", "en"},
+		{"zh-TW;q=0, zh-TW-x", "zh"},
+		{"fr, en;q=0", ""},
+	}
+
+	for _, test := range tests {
+		if got := DetermineLocaleFromHeader(test.header, available); got != test.expected {
+			t.Errorf("DetermineLocaleFromHeader(%q, available) should return %q, got: %q",
+				test.header, test.expected, got)
+		}
+	}
+}
+
+func TestDetermineLocaleFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Add("Accept-Language", "es")
+	r.Header.Add("Accept-Language", "zh-tw;q=0.8")
+
+	if got := DetermineLocaleFromRequest(r, available); got != "zh-TW" {
+		t.Errorf("DetermineLocaleFromRequest should return \"zh-TW\", got: %q", got)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRequestIDHeader is the header request IDs are read from and sent in
+// by default.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of a request ID accepted from a
+// client.
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// LoggingOptions controls the handler created with NewLoggingHandler.
+type LoggingOptions struct {
+	logger          *slog.Logger
+	requestIDHeader string
+	trustRequestID  bool
+}
+
+// NewLoggingOptions creates logging options that log to slog.Default() and
+// send request IDs in the DefaultRequestIDHeader.
+func NewLoggingOptions() *LoggingOptions {
+	return &LoggingOptions{
+		requestIDHeader: DefaultRequestIDHeader,
+	}
+}
+
+// WithLogger sets the logger requests are logged to.
+func (o *LoggingOptions) WithLogger(logger *slog.Logger) *LoggingOptions {
+	o.logger = logger
+	return o
+}
+
+// WithRequestIDHeader sets the header request IDs are sent in.
+func (o *LoggingOptions) WithRequestIDHeader(name string) *LoggingOptions {
+	o.requestIDHeader = name
+	return o
+}
+
+// WithTrustRequestID sets whether request IDs sent by the client, usually a
+// load balancer, in the request ID header are used instead of generating new
+// ones.
+func (o *LoggingOptions) WithTrustRequestID(trust bool) *LoggingOptions {
+	o.trustRequestID = trust
+	return o
+}
+
+// NewLoggingHandler takes a normal HTTP handler and logs every request it
+// serves as a structured slog record with the method, path, status, number of
+// bytes written, latency, request ID and locale.
+//
+// Each request is given an ID, which is sent back in the request ID header and
+// can be retrieved with RequestIDFromContext. The locale is the
+// Content-Language of the response as set by the handler created with
+// NewLocaleHandler, or the locale in the request context if the locale handler
+// wraps this one.
+//
+// Responses with a 5xx status are logged at the error level, all others at
+// the info level.
+func NewLoggingHandler(h http.Handler, opts *LoggingOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := ""
+		if opts.trustRequestID {
+			id = r.Header.Get(opts.requestIDHeader)
+		}
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(opts.requestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey{},
+			id))
+
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+
+		locale := w.Header().Get("Content-Language")
+		if locale == "" {
+			locale = LocaleFromContext(r.Context())
+		}
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		logger := opts.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("bytes", sw.bytes),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("request_id", id),
+			slog.String("locale", locale),
+		)
+	})
+}
+
+// RequestIDFromContext returns the request ID assigned by the handler created
+// with NewLoggingHandler. An empty string is returned if there is no request
+// ID in the context.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether id is safe to be used as a request ID.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// statusWriter records the status and the number of bytes of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, so that websockets keep working.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.status = http.StatusSwitchingProtocols
+		return hj.Hijack()
+	}
+	return nil, nil, errors.ErrUnsupported
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by
+// http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
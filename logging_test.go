@@ -0,0 +1,117 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestLogger returns a logger writing JSON records into buf.
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, nil))
+}
+
+// lastRecord decodes the last JSON record written into buf.
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	record := make(map[string]any)
+	if err := json.Unmarshal(lines[len(lines)-1], &record); err != nil {
+		t.Fatalf("Unable to decode log record: %v", err)
+	}
+	return record
+}
+
+func TestLoggingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	var id string
+	h := NewLoggingHandler(NewLocaleHandler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id = RequestIDFromContext(r.Context())
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		}), NewLocaleOptions("en", "zh-TW")),
+		NewLoggingOptions().WithLogger(newTestLogger(&buf)))
+
+	r := httptest.NewRequest("POST", "/zh-TW/items", nil)
+	r.Header.Set(DefaultRequestIDHeader, "client-id")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if id == "" || id == "client-id" {
+		t.Errorf("A new request ID should be generated, got: %q", id)
+	}
+	if got := w.Header().Get(DefaultRequestIDHeader); got != id {
+		t.Errorf("Request ID should be sent in the response, got: %q", got)
+	}
+
+	record := lastRecord(t, &buf)
+	expected := map[string]any{
+		"level":      "INFO",
+		"method":     "POST",
+		"path":       "/zh-TW/items",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(len("created")),
+		"request_id": id,
+		"locale":     "zh-TW",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s to be %v, got: %v", key, value, record[key])
+		}
+	}
+	if _, has := record["latency"]; !has {
+		t.Error("Latency should be logged")
+	}
+}
+
+func TestLoggingHandlerTrustRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLoggingHandler(okHandler, NewLoggingOptions().
+		WithLogger(newTestLogger(&buf)).WithTrustRequestID(true))
+
+	tests := []struct {
+		header  string
+		trusted bool
+	}{
+		{"lb-1234", true},
+		{"", false},
+		{"bad id", false},
+		{string(bytes.Repeat([]byte("a"), maxRequestIDLength+1)), false},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(DefaultRequestIDHeader, test.header)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		got := w.Header().Get(DefaultRequestIDHeader)
+		if test.trusted != (got == test.header) {
+			t.Errorf("Request ID %q trusted should be %t, got: %q",
+				test.header, test.trusted, got)
+		}
+		if record := lastRecord(t, &buf); record["status"] != float64(200) {
+			t.Errorf("Implicit status should be 200, got: %v",
+				record["status"])
+		}
+	}
+}
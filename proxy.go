@@ -0,0 +1,29 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/qqiao/webapp/v2/internal/proxy"
+)
+
+// isSecureRequest reports whether the request was made over HTTPS, trusting
+// the Forwarded and X-Forwarded-Proto headers only if the request comes
+// directly from one of the trusted proxies.
+func isSecureRequest(r *http.Request, trustedProxies []netip.Prefix) bool {
+	return proxy.IsSecureRequest(r, trustedProxies)
+}
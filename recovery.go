@@ -0,0 +1,93 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoveryOptions controls the handler created with NewRecoveryHandler.
+type RecoveryOptions struct {
+	logger       *slog.Logger
+	errorHandler http.Handler
+}
+
+// NewRecoveryOptions creates recovery options that log to slog.Default() and
+// respond with a plain 500 Internal Server Error.
+func NewRecoveryOptions() *RecoveryOptions {
+	return &RecoveryOptions{}
+}
+
+// WithLogger sets the logger panics are logged to.
+func (o *RecoveryOptions) WithLogger(logger *slog.Logger) *RecoveryOptions {
+	o.logger = logger
+	return o
+}
+
+// WithErrorHandler sets the handler that renders the response after a panic,
+// e.g. a custom error page. The handler must write an error status itself.
+func (o *RecoveryOptions) WithErrorHandler(h http.Handler) *RecoveryOptions {
+	o.errorHandler = h
+	return o
+}
+
+// NewRecoveryHandler takes a normal HTTP handler and turns panics raised while
+// serving a request, e.g. by GetTemplate failing to parse a template, into a
+// 500 Internal Server Error response. The panic is logged at the error level
+// along with the stack trace.
+//
+// If the response header has already been written, the response cannot be
+// replaced and is left as is. Panics with http.ErrAbortHandler are passed on,
+// so that the server aborts the response as intended.
+func NewRecoveryHandler(h http.Handler, opts *RecoveryOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			logger := opts.logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.LogAttrs(r.Context(), slog.LevelError, "panic",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+				slog.String("error", fmt.Sprint(v)),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			if sw.status != 0 {
+				return
+			}
+			if opts.errorHandler != nil {
+				opts.errorHandler.ServeHTTP(w, r)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+		}()
+		h.ServeHTTP(sw, r)
+	})
+}
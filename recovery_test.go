@@ -0,0 +1,92 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetTemplate("testdata/does-not-exist.html", true)
+	})
+	h := HSTSHandler(NewLoggingHandler(
+		NewRecoveryHandler(panicking, NewRecoveryOptions().WithLogger(logger)),
+		NewLoggingOptions().WithLogger(logger)).ServeHTTP)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got: %d", w.Code)
+	}
+	if w.Header().Get("Strict-Transport-Security") == "" {
+		t.Error("HSTS header should survive the panic")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a panic and a request record, got: %v", lines)
+	}
+	if !strings.Contains(lines[0], `"msg":"panic"`) ||
+		!strings.Contains(lines[0], "runtime/debug.Stack") {
+		t.Errorf("Panic should be logged with a stack trace, got: %s",
+			lines[0])
+	}
+	if record := lastRecord(t, &buf); record["level"] != "ERROR" ||
+		record["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("Request should be logged as an error, got: %v", record)
+	}
+}
+
+func TestRecoveryHandlerWrittenResponse(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewRecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("boom")
+	}), NewRecoveryOptions().WithLogger(newTestLogger(&buf)).
+		WithErrorHandler(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			t.Error("Error handler should not be called after writing")
+		})))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("Written responses should be left as is, got: %d %q", w.Code,
+			w.Body.String())
+	}
+}
+
+func TestRecoveryHandlerAbort(t *testing.T) {
+	h := NewRecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), NewRecoveryOptions())
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("ErrAbortHandler should be passed on, got: %v", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
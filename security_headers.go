@@ -0,0 +1,387 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// CSPNoncePlaceholder is replaced by a fresh nonce source, e.g.
+// 'nonce-3q2-7w', every time the Content-Security-Policy is sent.
+const CSPNoncePlaceholder = "{nonce}"
+
+// cspReportEndpoint is the name of the Reporting API endpoint CSP violations
+// are sent to.
+const cspReportEndpoint = "csp-endpoint"
+
+// maxCSPReportSize is the maximum size of a CSP report request body.
+const maxCSPReportSize = 64 << 10
+
+type cspNonceContextKey struct{}
+
+func init() {
+	RegisterRequestTemplateFuncs(cspNonceFuncs)
+}
+
+// cspNonceFuncs returns the cspNonce template function bound to the nonce of
+// the request.
+func cspNonceFuncs(r *http.Request) template.FuncMap {
+	nonce := CSPNonceFromContext(r.Context())
+	return template.FuncMap{
+		"cspNonce": func() string { return nonce },
+	}
+}
+
+// SecurityHeadersOptions controls the headers sent by the handler created
+// with NewSecurityHeadersHandler.
+type SecurityHeadersOptions struct {
+	contentSecurityPolicy   string
+	reportOnly              bool
+	reportURI               string
+	frameAncestors          []string
+	noSniff                 bool
+	referrerPolicy          string
+	permissionsPolicy       string
+	crossOriginOpenerPolicy string
+}
+
+// NewSecurityHeadersOptions creates security header options with the
+// following defaults:
+//
+//	X-Content-Type-Options: nosniff
+//	Referrer-Policy: strict-origin-when-cross-origin
+//	Permissions-Policy: camera=(), geolocation=(), microphone=()
+//	Cross-Origin-Opener-Policy: same-origin
+//
+// No Content-Security-Policy is sent until either a policy or frame ancestors
+// are configured.
+func NewSecurityHeadersOptions() *SecurityHeadersOptions {
+	return &SecurityHeadersOptions{
+		noSniff:                 true,
+		referrerPolicy:          "strict-origin-when-cross-origin",
+		permissionsPolicy:       "camera=(), geolocation=(), microphone=()",
+		crossOriginOpenerPolicy: "same-origin",
+	}
+}
+
+// WithContentSecurityPolicy sets the Content-Security-Policy.
+//
+// Every occurrence of CSPNoncePlaceholder in the policy is replaced by a nonce
+// source that is generated afresh for every request, e.g.
+//
+//	script-src 'self' {nonce}; style-src 'self' {nonce}
+//
+// The nonce is available to handlers through CSPNonceFromContext, and to
+// templates executed with ExecuteTemplate through the cspNonce function:
+//
+//	<script nonce="{{cspNonce}}">...</script>
+//
+// Like all request template functions, the nonce is bound by ExecuteTemplate
+// cloning the template for every request. Templates executed directly with
+// Execute get an empty nonce, which does not match the policy.
+func (o *SecurityHeadersOptions) WithContentSecurityPolicy(
+	policy string) *SecurityHeadersOptions {
+	o.contentSecurityPolicy = policy
+	return o
+}
+
+// WithReportOnly sets whether the policy is sent as
+// Content-Security-Policy-Report-Only, so that violations are only reported
+// and not enforced.
+func (o *SecurityHeadersOptions) WithReportOnly(
+	reportOnly bool) *SecurityHeadersOptions {
+	o.reportOnly = reportOnly
+	return o
+}
+
+// WithReportURI sets the URI violations of the policy are reported to, it is
+// usually served by a handler created with NewCSPReportHandler.
+//
+// Both the report-uri directive and the newer Reporting API report-to
+// directive are sent, so that all browsers report violations.
+func (o *SecurityHeadersOptions) WithReportURI(uri string) *SecurityHeadersOptions {
+	o.reportURI = uri
+	return o
+}
+
+// WithFrameAncestors sets the frame-ancestors directive of the policy, which
+// controls who may embed the pages, e.g. 'none' or 'self'.
+func (o *SecurityHeadersOptions) WithFrameAncestors(
+	sources ...string) *SecurityHeadersOptions {
+	o.frameAncestors = sources
+	return o
+}
+
+// WithNoSniff sets whether X-Content-Type-Options: nosniff is sent.
+func (o *SecurityHeadersOptions) WithNoSniff(noSniff bool) *SecurityHeadersOptions {
+	o.noSniff = noSniff
+	return o
+}
+
+// WithReferrerPolicy sets the Referrer-Policy. An empty policy disables the
+// header.
+func (o *SecurityHeadersOptions) WithReferrerPolicy(
+	policy string) *SecurityHeadersOptions {
+	o.referrerPolicy = policy
+	return o
+}
+
+// WithPermissionsPolicy sets the Permissions-Policy. An empty policy disables
+// the header.
+func (o *SecurityHeadersOptions) WithPermissionsPolicy(
+	policy string) *SecurityHeadersOptions {
+	o.permissionsPolicy = policy
+	return o
+}
+
+// WithCrossOriginOpenerPolicy sets the Cross-Origin-Opener-Policy. An empty
+// policy disables the header.
+func (o *SecurityHeadersOptions) WithCrossOriginOpenerPolicy(
+	policy string) *SecurityHeadersOptions {
+	o.crossOriginOpenerPolicy = policy
+	return o
+}
+
+// policy returns the complete Content-Security-Policy, with the nonce
+// placeholder still in place.
+func (o *SecurityHeadersOptions) policy() string {
+	directives := make([]string, 0)
+	if p := strings.TrimSpace(strings.TrimSuffix(
+		strings.TrimSpace(o.contentSecurityPolicy), ";")); p != "" {
+		directives = append(directives, p)
+	}
+	if len(o.frameAncestors) > 0 {
+		directives = append(directives,
+			"frame-ancestors "+strings.Join(o.frameAncestors, " "))
+	}
+	if len(directives) > 0 && o.reportURI != "" {
+		directives = append(directives, "report-uri "+o.reportURI,
+			"report-to "+cspReportEndpoint)
+	}
+	return strings.Join(directives, "; ")
+}
+
+// NewSecurityHeadersHandler takes a normal HTTP handler and adds security
+// related headers to all of its responses.
+//
+// If the Content-Security-Policy contains CSPNoncePlaceholder, a new nonce is
+// generated for every request and stored in the request context.
+func NewSecurityHeadersHandler(h http.Handler,
+	opts *SecurityHeadersOptions) http.Handler {
+	policy := opts.policy()
+	useNonce := strings.Contains(policy, CSPNoncePlaceholder)
+	policyHeader := "Content-Security-Policy"
+	if opts.reportOnly {
+		policyHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	static := http.Header{}
+	if opts.noSniff {
+		static.Set("X-Content-Type-Options", "nosniff")
+	}
+	if opts.referrerPolicy != "" {
+		static.Set("Referrer-Policy", opts.referrerPolicy)
+	}
+	if opts.permissionsPolicy != "" {
+		static.Set("Permissions-Policy", opts.permissionsPolicy)
+	}
+	if opts.crossOriginOpenerPolicy != "" {
+		static.Set("Cross-Origin-Opener-Policy", opts.crossOriginOpenerPolicy)
+	}
+	if policy != "" && opts.reportURI != "" {
+		static.Set("Reporting-Endpoints",
+			cspReportEndpoint+`="`+opts.reportURI+`"`)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		for name := range static {
+			header.Set(name, static.Get(name))
+		}
+
+		if useNonce {
+			nonce := newCSPNonce()
+			header.Set(policyHeader, strings.ReplaceAll(policy,
+				CSPNoncePlaceholder, "'nonce-"+nonce+"'"))
+			r = r.WithContext(context.WithValue(r.Context(),
+				cspNonceContextKey{}, nonce))
+		} else if policy != "" {
+			header.Set(policyHeader, policy)
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// CSPNonceFromContext returns the nonce generated for the request by the
+// handler created with NewSecurityHeadersHandler. An empty string is returned
+// if there is no nonce in the context.
+func CSPNonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceContextKey{}).(string)
+	return nonce
+}
+
+// newCSPNonce generates a random nonce. The URL safe alphabet is used, so that
+// the nonce does not need to be escaped in HTML attributes.
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error.
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CSPReport is a Content Security Policy violation report.
+type CSPReport struct {
+	DocumentURL        string `json:"documentURL,omitempty"`
+	Referrer           string `json:"referrer,omitempty"`
+	BlockedURL         string `json:"blockedURL,omitempty"`
+	EffectiveDirective string `json:"effectiveDirective,omitempty"`
+	OriginalPolicy     string `json:"originalPolicy,omitempty"`
+	Disposition        string `json:"disposition,omitempty"`
+	StatusCode         int    `json:"statusCode,omitempty"`
+	SourceFile         string `json:"sourceFile,omitempty"`
+	LineNumber         int    `json:"lineNumber,omitempty"`
+	ColumnNumber       int    `json:"columnNumber,omitempty"`
+	Sample             string `json:"sample,omitempty"`
+}
+
+// legacyCSPReport is the report format sent to report-uri endpoints.
+type legacyCSPReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		StatusCode         int    `json:"status-code"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// CSPReporter receives the CSP violation reports collected by the handler
+// created with NewCSPReportHandler.
+type CSPReporter interface {
+	// ReportCSPViolation is called once for every violation report received.
+	ReportCSPViolation(r *http.Request, report CSPReport)
+}
+
+// CSPReporterFunc is an adapter to allow the use of ordinary functions as
+// CSPReporter.
+type CSPReporterFunc func(r *http.Request, report CSPReport)
+
+// ReportCSPViolation calls f(r, report).
+func (f CSPReporterFunc) ReportCSPViolation(r *http.Request, report CSPReport) {
+	f(r, report)
+}
+
+// LogCSPReporter is a CSPReporter that logs all reports with the standard
+// logger.
+var LogCSPReporter = CSPReporterFunc(func(r *http.Request, report CSPReport) {
+	log.Printf("CSP violation: %s blocked %q on %s", report.EffectiveDirective,
+		report.BlockedURL, report.DocumentURL)
+})
+
+// NewCSPReportHandler creates a handler that collects CSP violation reports
+// and passes them on to the reporter.
+//
+// Both the legacy application/csp-report format used by report-uri and the
+// application/reports+json format of the Reporting API are accepted.
+func NewCSPReportHandler(reporter CSPReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCSPReportSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		reports, err := parseCSPReports(r.Header.Get("Content-Type"), body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, report := range reports {
+			reporter.ReportCSPViolation(r, report)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// parseCSPReports parses the body of a report request according to its
+// content type.
+func parseCSPReports(contentType string, body []byte) ([]CSPReport, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/reports+json" {
+		var reports []struct {
+			Type string    `json:"type"`
+			Body CSPReport `json:"body"`
+		}
+		if err := json.Unmarshal(body, &reports); err != nil {
+			return nil, err
+		}
+
+		result := make([]CSPReport, 0, len(reports))
+		for _, report := range reports {
+			if report.Type == "csp-violation" {
+				result = append(result, report.Body)
+			}
+		}
+		return result, nil
+	}
+
+	var legacy legacyCSPReport
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	report := legacy.Report
+	directive := report.EffectiveDirective
+	if directive == "" {
+		directive = report.ViolatedDirective
+	}
+	return []CSPReport{{
+		DocumentURL:        report.DocumentURI,
+		Referrer:           report.Referrer,
+		BlockedURL:         report.BlockedURI,
+		EffectiveDirective: directive,
+		OriginalPolicy:     report.OriginalPolicy,
+		Disposition:        report.Disposition,
+		StatusCode:         report.StatusCode,
+		SourceFile:         report.SourceFile,
+		LineNumber:         report.LineNumber,
+		ColumnNumber:       report.ColumnNumber,
+		Sample:             report.ScriptSample,
+	}}, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecurityHeadersHandler(t *testing.T) {
+	t.Run("Defaults should not send a CSP", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewSecurityHeadersHandler(okHandler, NewSecurityHeadersOptions()).
+			ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		expected := map[string]string{
+			"X-Content-Type-Options":     "nosniff",
+			"Referrer-Policy":            "strict-origin-when-cross-origin",
+			"Permissions-Policy":         "camera=(), geolocation=(), microphone=()",
+			"Cross-Origin-Opener-Policy": "same-origin",
+			"Content-Security-Policy":    "",
+			"Reporting-Endpoints":        "",
+		}
+		for name, value := range expected {
+			if got := w.Header().Get(name); got != value {
+				t.Errorf("Expected %s to be %q, got: %q", name, value, got)
+			}
+		}
+	})
+
+	t.Run("Nonces should be fresh and exposed to templates", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{
+			"nonce.html": `<script nonce="{{cspNonce}}"></script>`,
+		})
+		tmpl := GetTemplate(filepath.Join(dir, "nonce.html"), false)
+
+		var body bytes.Buffer
+		h := NewSecurityHeadersHandler(http.HandlerFunc(func(
+			w http.ResponseWriter, r *http.Request) {
+			body.Reset()
+			if err := ExecuteTemplate(&body, r, tmpl, nil); err != nil {
+				t.Fatalf("Unable to execute template: %v", err)
+			}
+		}), NewSecurityHeadersOptions().
+			WithContentSecurityPolicy("script-src 'self' {nonce};").
+			WithFrameAncestors("'none'").
+			WithReferrerPolicy(""))
+
+		nonces := make(map[string]bool)
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			policy := w.Header().Get("Content-Security-Policy")
+			nonce := strings.TrimSuffix(strings.TrimPrefix(body.String(),
+				`<script nonce="`), `"></script>`)
+			expected := "script-src 'self' 'nonce-" + nonce +
+				"'; frame-ancestors 'none'"
+			if nonce == "" || policy != expected {
+				t.Errorf("Expected policy %q, got: %q", expected, policy)
+			}
+			if w.Header().Get("Referrer-Policy") != "" {
+				t.Error("Referrer-Policy should be disabled")
+			}
+			nonces[nonce] = true
+		}
+		if len(nonces) != 2 {
+			t.Error("A fresh nonce should be generated for every request")
+		}
+	})
+
+	t.Run("Templates executed directly should get an empty nonce",
+		func(t *testing.T) {
+			dir := writeTemplates(t, map[string]string{
+				"nonce.html": `<script nonce="{{cspNonce}}"></script>`,
+			})
+
+			var body bytes.Buffer
+			if err := GetTemplate(filepath.Join(dir, "nonce.html"), false).
+				Execute(&body, nil); err != nil {
+				t.Fatalf("Unable to execute template: %v", err)
+			}
+			if got := body.String(); got != `<script nonce=""></script>` {
+				t.Errorf("Expected an empty nonce, got: %q", got)
+			}
+		})
+
+	t.Run("Report only mode should use a different header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewSecurityHeadersHandler(okHandler, NewSecurityHeadersOptions().
+			WithContentSecurityPolicy("default-src 'self'").
+			WithReportOnly(true).
+			WithReportURI("/csp-reports")).
+			ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		expected := "default-src 'self'; report-uri /csp-reports; " +
+			"report-to csp-endpoint"
+		if got := w.Header().Get("Content-Security-Policy-Report-Only"); got != expected {
+			t.Errorf("Expected %q, got: %q", expected, got)
+		}
+		if w.Header().Get("Content-Security-Policy") != "" {
+			t.Error("Content-Security-Policy should not be sent in report only mode")
+		}
+		if got := w.Header().Get("Reporting-Endpoints"); got != `csp-endpoint="/csp-reports"` {
+			t.Errorf("Unexpected Reporting-Endpoints: %q", got)
+		}
+	})
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	reports := make([]CSPReport, 0)
+	h := NewCSPReportHandler(CSPReporterFunc(func(r *http.Request,
+		report CSPReport) {
+		reports = append(reports, report)
+	}))
+
+	post := func(contentType string, body string) int {
+		r := httptest.NewRequest("POST", "/csp-reports", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post("application/csp-report", `{"csp-report": {
+		"document-uri": "https://example.com/",
+		"blocked-uri": "https://evil.example.com/x.js",
+		"violated-directive": "script-src-elem",
+		"line-number": 10
+	}}`); code != http.StatusNoContent {
+		t.Errorf("Expected 204, got: %d", code)
+	}
+	if code := post("application/reports+json", `[{
+		"type": "csp-violation",
+		"body": {"documentURL": "https://example.com/a", "effectiveDirective": "img-src"}
+	}, {"type": "deprecation", "body": {}}]`); code != http.StatusNoContent {
+		t.Errorf("Expected 204, got: %d", code)
+	}
+	if code := post("application/csp-report", `{`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got: %d", code)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got: %v", reports)
+	}
+	if reports[0].EffectiveDirective != "script-src-elem" ||
+		reports[0].BlockedURL != "https://evil.example.com/x.js" ||
+		reports[0].LineNumber != 10 {
+		t.Errorf("Legacy report not parsed correctly: %+v", reports[0])
+	}
+	if reports[1].DocumentURL != "https://example.com/a" ||
+		reports[1].EffectiveDirective != "img-src" {
+		t.Errorf("Reporting API report not parsed correctly: %+v", reports[1])
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/csp-reports", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got: %d", w.Code)
+	}
+}
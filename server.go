@@ -0,0 +1,424 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Default values used by Server.
+const (
+	DefaultHealthzPath     = "/healthz"
+	DefaultReadyzPath      = "/readyz"
+	DefaultShutdownTimeout = 30 * time.Second
+	DefaultCheckTimeout    = 5 * time.Second
+)
+
+// Checker checks whether a component the application depends on, such as a
+// datastore, is working.
+type Checker interface {
+	// Check returns an error if the component is not working.
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ShutdownHook is run when the server shuts down, after all the in-flight
+// requests have finished, e.g. to close database clients.
+type ShutdownHook func(ctx context.Context) error
+
+// CloserHook returns a shutdown hook that closes c, e.g. a firestore client.
+func CloserHook(c io.Closer) ShutdownHook {
+	return func(context.Context) error {
+		return c.Close()
+	}
+}
+
+// Server wraps an http.Server and manages its lifecycle.
+//
+// The server shuts down gracefully when the process receives SIGINT or
+// SIGTERM: it reports itself as not ready, stops accepting connections, waits
+// for in-flight requests to finish within the shutdown timeout and then runs
+// the shutdown hooks.
+//
+// The server also answers health checks: the liveness endpoint, /healthz by
+// default, responds with 200 OK as long as the server is running, while the
+// readiness endpoint, /readyz by default, runs all the readiness checks and
+// responds with 503 Service Unavailable if any of them fails or the server is
+// shutting down. Both respond with a JSON HealthReport, which for readiness
+// includes the status and latency of every check, e.g.
+//
+//	{
+//		"status": "unavailable",
+//		"checks": {
+//			"jwt": {"status": "ok", "latency_ms": 1.2},
+//			"users": {"status": "error", "latency_ms": 2000.4,
+//				"error": "context deadline exceeded"}
+//		}
+//	}
+type Server struct {
+	server          *http.Server
+	healthzPath     string
+	readyzPath      string
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+	checkTimeout    time.Duration
+	signals         []os.Signal
+
+	mu       sync.Mutex
+	hooks    []ShutdownHook
+	checkers map[string]Checker
+
+	shuttingDown atomic.Bool
+	shutdownOnce sync.Once
+	done         chan struct{}
+	shutdownErr  error
+}
+
+// NewServer creates a server that listens on addr and serves h.
+func NewServer(addr string, h http.Handler) *Server {
+	s := &Server{
+		healthzPath:     DefaultHealthzPath,
+		readyzPath:      DefaultReadyzPath,
+		shutdownTimeout: DefaultShutdownTimeout,
+		checkTimeout:    DefaultCheckTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		checkers:        make(map[string]Checker),
+		done:            make(chan struct{}),
+	}
+	s.server = &http.Server{
+		Addr:    addr,
+		Handler: s.handler(h),
+	}
+	return s
+}
+
+// HTTPServer returns the underlying http.Server, so that settings such as
+// timeouts and the TLS configuration can be changed. Its Handler must not be
+// replaced.
+func (s *Server) HTTPServer() *http.Server {
+	return s.server
+}
+
+// WithHealthPaths sets the paths of the liveness and readiness endpoints. An
+// empty path disables the endpoint.
+func (s *Server) WithHealthPaths(healthz string, readyz string) *Server {
+	s.healthzPath = healthz
+	s.readyzPath = readyz
+	return s
+}
+
+// WithShutdownTimeout sets how long in-flight requests are given to finish,
+// and how long the shutdown hooks are given to run. Connections still open
+// after the timeout are closed forcibly.
+func (s *Server) WithShutdownTimeout(timeout time.Duration) *Server {
+	s.shutdownTimeout = timeout
+	return s
+}
+
+// WithShutdownDelay sets how long the server keeps accepting connections
+// while reporting itself as not ready, before it starts draining. This gives
+// load balancers time to notice and stop routing new requests to it.
+func (s *Server) WithShutdownDelay(delay time.Duration) *Server {
+	s.shutdownDelay = delay
+	return s
+}
+
+// WithCheckTimeout sets how long each readiness check is given to finish.
+func (s *Server) WithCheckTimeout(timeout time.Duration) *Server {
+	s.checkTimeout = timeout
+	return s
+}
+
+// WithSignals sets the signals that trigger a graceful shutdown.
+func (s *Server) WithSignals(signals ...os.Signal) *Server {
+	s.signals = signals
+	return s
+}
+
+// OnShutdown registers hooks to be run on shutdown. Hooks are run in reverse
+// order of registration, the same way as deferred calls, so that resources
+// are released in reverse order of acquisition.
+func (s *Server) OnShutdown(hooks ...ShutdownHook) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, hooks...)
+	return s
+}
+
+// AddReadinessCheck registers a checker that must pass for the server to be
+// ready, e.g. a user.FirestoreManager, a rememberme.FirestoreTokenManager or a
+// jwt.Checker. Registering a name again replaces the checker.
+func (s *Server) AddReadinessCheck(name string, checker Checker) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkers[name] = checker
+	return s
+}
+
+// ListenAndServe listens on the address of the server and serves requests
+// until the server is shut down, either by a signal or by Shutdown.
+//
+// Unlike http.Server.ListenAndServe, it only returns once the shutdown is
+// complete, with nil if the shutdown was clean.
+func (s *Server) ListenAndServe() error {
+	addr := s.server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Join(err, s.Shutdown(context.Background()))
+	}
+	return s.Serve(l)
+}
+
+// ListenAndServeTLS works the same way as ListenAndServe, except that it
+// serves HTTPS with the given certificate and key files.
+func (s *Server) ListenAndServeTLS(certFile string, keyFile string) error {
+	return s.serve(func() error {
+		return s.server.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// Serve serves requests on l until the server is shut down, either by a
+// signal or by Shutdown. It only returns once the shutdown is complete.
+func (s *Server) Serve(l net.Listener) error {
+	return s.serve(func() error {
+		return s.server.Serve(l)
+	})
+}
+
+// Shutdown shuts the server down gracefully and runs the shutdown hooks. It
+// is called automatically when the process receives one of the signals, but
+// can also be called directly.
+//
+// Errors of draining the connections and of all the hooks are joined into the
+// returned error. Subsequent calls wait for the first shutdown to complete
+// and return the same result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		defer close(s.done)
+		s.shuttingDown.Store(true)
+
+		select {
+		case <-time.After(s.shutdownDelay):
+		case <-ctx.Done():
+		}
+
+		errs := make([]error, 0)
+		drainCtx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+		if err := s.server.Shutdown(drainCtx); err != nil {
+			errs = append(errs, err, s.server.Close())
+		}
+		cancel()
+
+		s.mu.Lock()
+		hooks := append([]ShutdownHook(nil), s.hooks...)
+		s.mu.Unlock()
+
+		hookCtx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+		for i := len(hooks) - 1; i >= 0; i-- {
+			errs = append(errs, hooks[i](hookCtx))
+		}
+		s.shutdownErr = errors.Join(errs...)
+	})
+
+	<-s.done
+	return s.shutdownErr
+}
+
+// serve runs the serving function until it fails or the server is shut down.
+func (s *Server) serve(fn func() error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), s.signals...)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- fn()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			// Shutdown has been called directly.
+			<-s.done
+			return s.shutdownErr
+		}
+		return errors.Join(err, s.Shutdown(context.Background()))
+	case <-ctx.Done():
+		stop()
+		return s.Shutdown(context.Background())
+	}
+}
+
+// handler wraps h with the health check endpoints.
+func (s *Server) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case s.healthzPath != "" && r.URL.Path == s.healthzPath:
+			writeHealth(w, HealthReport{Status: HealthStatusOK})
+		case s.readyzPath != "" && r.URL.Path == s.readyzPath:
+			s.serveReadiness(w, r)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Health statuses reported by the health check endpoints.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusError       = "error"
+)
+
+// HealthReport is the JSON body of the health check responses.
+type HealthReport struct {
+	// Status is HealthStatusOK if the server is healthy, and
+	// HealthStatusUnavailable otherwise.
+	Status string `json:"status"`
+
+	// Checks holds the results of the readiness checks by name.
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single readiness check.
+type CheckResult struct {
+	// Status is HealthStatusOK if the check passed, and HealthStatusError
+	// otherwise.
+	Status string `json:"status"`
+
+	// LatencyMS is how long the check took in milliseconds.
+	LatencyMS float64 `json:"latency_ms"`
+
+	// Error is the error returned by the check, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// serveReadiness runs all the readiness checks concurrently and reports the
+// results. Checks that have not finished by the time the check timeout
+// expires, or the request is cancelled, are reported as failed without being
+// waited for, even if they ignore their context.
+func (s *Server) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	if s.shuttingDown.Load() {
+		writeHealth(w, HealthReport{Status: HealthStatusUnavailable})
+		return
+	}
+
+	s.mu.Lock()
+	checkers := maps.Clone(s.checkers)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.checkTimeout)
+	defer cancel()
+
+	report := HealthReport{
+		Status: HealthStatusOK,
+		Checks: make(map[string]CheckResult, len(checkers)),
+	}
+	var mu sync.Mutex
+	// reported is set once the report is written, so that checks finishing
+	// later no longer touch it.
+	reported := false
+	var wg sync.WaitGroup
+	start := time.Now()
+	for name, checker := range checkers {
+		wg.Go(func() {
+			err := checker.Check(ctx)
+			result := CheckResult{
+				Status:    HealthStatusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status, result.Error = HealthStatusError, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if reported {
+				return
+			}
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = HealthStatusUnavailable
+			}
+		})
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	reported = true
+	for name := range checkers {
+		if _, has := report.Checks[name]; !has {
+			report.Checks[name] = CheckResult{
+				Status:    HealthStatusError,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+				Error:     ctx.Err().Error(),
+			}
+			report.Status = HealthStatusUnavailable
+		}
+	}
+	writeHealth(w, report)
+}
+
+// writeHealth writes a health check response that must not be cached. The
+// status code is 200 OK if the report is ok, and 503 Service Unavailable
+// otherwise.
+func writeHealth(w http.ResponseWriter, report HealthReport) {
+	code := http.StatusOK
+	if report.Status != HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Unable to write health report: %v", err)
+	}
+}
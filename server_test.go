@@ -0,0 +1,274 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startServer serves s on a random local port and returns its base URL along
+// with a channel receiving the result of Serve.
+func startServer(t *testing.T, s *Server) (string, <-chan error) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- s.Serve(l)
+	}()
+
+	url := "http://" + l.Addr().String()
+	if !eventually(func() bool {
+		res, err := http.Get(url + DefaultHealthzPath)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}) {
+		t.Fatal("Server should start serving")
+	}
+	return url, result
+}
+
+func TestServerHealth(t *testing.T) {
+	var failing bool
+	s := NewServer("", okHandler).
+		AddReadinessCheck("datastore", CheckerFunc(func(ctx context.Context) error {
+			if failing {
+				return errors.New("unreachable")
+			}
+			return nil
+		})).
+		AddReadinessCheck("keys", CheckerFunc(func(ctx context.Context) error {
+			return nil
+		}))
+
+	serve := func(path string) (*httptest.ResponseRecorder, HealthReport) {
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var report HealthReport
+		if path != "/" {
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Errorf("%s should respond with JSON: %v", path, err)
+			}
+		}
+		return w, report
+	}
+
+	if w, _ := serve("/"); w.Body.String() != "ok" {
+		t.Errorf("Other requests should be passed on, got: %q", w.Body.String())
+	}
+	if w, report := serve(DefaultHealthzPath); w.Code != http.StatusOK ||
+		report.Status != HealthStatusOK {
+		t.Errorf("Liveness should be ok, got: %d %v", w.Code, report)
+	}
+
+	w, report := serve(DefaultReadyzPath)
+	if w.Code != http.StatusOK || report.Status != HealthStatusOK ||
+		len(report.Checks) != 2 {
+		t.Errorf("Readiness should be ok, got: %d %v", w.Code, report)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON content type, got: %q", ct)
+	}
+
+	failing = true
+	w, report = serve(DefaultReadyzPath)
+	if w.Code != http.StatusServiceUnavailable ||
+		report.Status != HealthStatusUnavailable {
+		t.Errorf("Failing checks should make the server unavailable, got: "+
+			"%d %v", w.Code, report)
+	}
+	if result := report.Checks["datastore"]; result.Status != HealthStatusError ||
+		result.Error != "unreachable" || result.LatencyMS < 0 {
+		t.Errorf("Failing check should be reported, got: %v", result)
+	}
+	if result := report.Checks["keys"]; result.Status != HealthStatusOK {
+		t.Errorf("Passing check should be reported, got: %v", result)
+	}
+	if w, _ = serve(DefaultHealthzPath); w.Code != http.StatusOK {
+		t.Errorf("Liveness should not run checks, got: %d", w.Code)
+	}
+}
+
+func TestServerCheckTimeout(t *testing.T) {
+	s := NewServer("", okHandler).WithCheckTimeout(10*time.Millisecond).
+		AddReadinessCheck("slow", CheckerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", DefaultReadyzPath,
+		nil))
+	var report HealthReport
+	json.NewDecoder(w.Body).Decode(&report)
+	if result := report.Checks["slow"]; w.Code != http.StatusServiceUnavailable ||
+		result.Error != context.DeadlineExceeded.Error() ||
+		result.LatencyMS < 10 {
+		t.Errorf("Slow checks should time out, got: %d %v", w.Code, result)
+	}
+}
+
+func TestServerCheckIgnoringContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	s := NewServer("", okHandler).WithCheckTimeout(10*time.Millisecond).
+		AddReadinessCheck("stuck", CheckerFunc(func(ctx context.Context) error {
+			<-release
+			return nil
+		})).
+		AddReadinessCheck("fast", CheckerFunc(func(ctx context.Context) error {
+			return nil
+		}))
+
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", DefaultReadyzPath,
+		nil))
+	var report HealthReport
+	json.NewDecoder(w.Body).Decode(&report)
+	if result := report.Checks["stuck"]; w.Code != http.StatusServiceUnavailable ||
+		result.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Stuck checks should not be waited for, got: %d %v", w.Code,
+			result)
+	}
+	if result := report.Checks["fast"]; result.Status != HealthStatusOK {
+		t.Errorf("Finished checks should be reported, got: %v", result)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := NewServer("", http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "drained")
+	}))
+
+	var mu sync.Mutex
+	order := make([]string, 0)
+	hook := func(name string, err error) ShutdownHook {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return err
+		}
+	}
+	hookErr := errors.New("hook failed")
+	s.OnShutdown(hook("first", nil), hook("second", hookErr))
+
+	url, result := startServer(t, s)
+
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get(url + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		body <- string(b)
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+
+	if !eventually(s.shuttingDown.Load) {
+		t.Fatal("Server should start shutting down")
+	}
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", DefaultReadyzPath,
+		nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Shutting down servers should not be ready, got: %d", w.Code)
+	}
+
+	close(release)
+	if got := <-body; got != "drained" {
+		t.Errorf("In-flight requests should be drained, got: %q", got)
+	}
+	if err := <-shutdown; !errors.Is(err, hookErr) {
+		t.Errorf("Hook errors should be returned, got: %v", err)
+	}
+	if err := <-result; !errors.Is(err, hookErr) {
+		t.Errorf("Serve should return the shutdown result, got: %v", err)
+	}
+	if strings.Join(order, ",") != "second,first" {
+		t.Errorf("Hooks should run in reverse order, got: %v", order)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("Server should stop accepting connections")
+	}
+}
+
+func TestServerSignal(t *testing.T) {
+	closed := make(chan struct{})
+	s := NewServer("", okHandler).
+		WithSignals(syscall.SIGUSR1).
+		WithShutdownTimeout(time.Second).
+		OnShutdown(CloserHook(closerFunc(func() error {
+			close(closed)
+			return nil
+		})))
+
+	_, result := startServer(t, s)
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Clean shutdown should return nil, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server should shut down on signal")
+	}
+	select {
+	case <-closed:
+	default:
+		t.Error("Closer hook should be run")
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"strings"
+	"time"
+)
+
+// maxCookieSize is the maximum size of a cookie value accepted by all
+// browsers.
+const maxCookieSize = 4096
+
+// cookieSession is the encoded form of a session kept in a cookie.
+type cookieSession struct {
+	ID      string
+	Values  map[string]any
+	Expires time.Time
+}
+
+// CookieStore is a Store implementation that keeps the entire session in the
+// session cookie.
+//
+// Cookies are signed with HMAC-SHA256 so that they cannot be tampered with,
+// and can optionally be encrypted with AES-GCM so that their content cannot be
+// read by the client. Values are encoded with encoding/gob, so custom types
+// must be registered with gob.Register.
+//
+// Since the session only lives in the cookie, a CookieStore cannot revoke a
+// session before it expires, and Delete does nothing.
+type CookieStore struct {
+	hashKeys  [][]byte
+	blockKeys [][]byte
+}
+
+// NewCookieStore creates a cookie store that signs cookies with the given
+// hash keys, which should be at least 32 random bytes each.
+//
+// The first key is used for signing, while cookies signed with any of the
+// keys are accepted, so keys can be rotated by prepending a new key and
+// dropping the oldest one once all cookies signed with it have expired.
+//
+// ErrNoHashKey is returned if no keys are given, or if any of them is empty.
+func NewCookieStore(hashKeys ...[]byte) (*CookieStore, error) {
+	if len(hashKeys) == 0 {
+		return nil, ErrNoHashKey
+	}
+	for _, key := range hashKeys {
+		if len(key) == 0 {
+			return nil, ErrNoHashKey
+		}
+	}
+	return &CookieStore{hashKeys: hashKeys}, nil
+}
+
+// WithEncryptionKeys sets the keys cookies are encrypted with. Each key must
+// be 16, 24 or 32 bytes long, to select AES-128, AES-192 or AES-256.
+//
+// Just like the hash keys, the first key is used for encryption, while all
+// the keys are tried for decryption.
+func (s *CookieStore) WithEncryptionKeys(blockKeys ...[]byte) *CookieStore {
+	s.blockKeys = blockKeys
+	return s
+}
+
+// Load decodes the session kept in the cookie value.
+func (s *CookieStore) Load(_ context.Context, value string) (<-chan *Session,
+	<-chan error) {
+	sessionCh := make(chan *Session)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(sessionCh)
+		defer close(errCh)
+
+		session, err := s.decode(value)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		sessionCh <- session
+	}()
+
+	return sessionCh, errCh
+}
+
+// Save encodes the session into a cookie value.
+//
+// This method returns ErrSessionTooLarge if the cookie value would exceed the
+// size browsers accept.
+func (s *CookieStore) Save(_ context.Context, session *Session) (<-chan string,
+	<-chan error) {
+	valueCh := make(chan string)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(valueCh)
+		defer close(errCh)
+
+		value, err := s.encode(session)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		valueCh <- value
+	}()
+
+	return valueCh, errCh
+}
+
+// Delete does nothing, since cookie sessions are not stored on the server.
+func (s *CookieStore) Delete(context.Context, string) <-chan error {
+	errCh := make(chan error)
+	close(errCh)
+	return errCh
+}
+
+func (s *CookieStore) encode(session *Session) (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cookieSession{
+		ID:      session.ID,
+		Values:  session.Values,
+		Expires: session.Expires,
+	}); err != nil {
+		return "", err
+	}
+
+	data := buf.Bytes()
+	if len(s.blockKeys) > 0 {
+		aead, err := newAEAD(s.blockKeys[0])
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		rand.Read(nonce)
+		data = aead.Seal(nonce, nonce, data, nil)
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(data) + "." +
+		base64.RawURLEncoding.EncodeToString(sign(s.hashKeys[0], data))
+	if len(value) > maxCookieSize {
+		return "", ErrSessionTooLarge
+	}
+	return value, nil
+}
+
+func (s *CookieStore) decode(value string) (*Session, error) {
+	encoded, encodedMAC, found := strings.Cut(value, ".")
+	if !found {
+		return nil, ErrSessionInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrSessionInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, ErrSessionInvalid
+	}
+
+	verified := false
+	for _, key := range s.hashKeys {
+		if hmac.Equal(mac, sign(key, data)) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrSessionInvalid
+	}
+
+	if len(s.blockKeys) > 0 {
+		if data, err = s.decrypt(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var decoded cookieSession
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return nil, ErrSessionInvalid
+	}
+	if !decoded.Expires.After(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+	if decoded.Values == nil {
+		decoded.Values = make(map[string]any)
+	}
+	return &Session{
+		ID:      decoded.ID,
+		Values:  decoded.Values,
+		Expires: decoded.Expires,
+	}, nil
+}
+
+// decrypt tries all the encryption keys on data.
+func (s *CookieStore) decrypt(data []byte) ([]byte, error) {
+	for _, key := range s.blockKeys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < aead.NonceSize() {
+			return nil, ErrSessionInvalid
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrSessionInvalid
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sign(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
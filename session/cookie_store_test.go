@@ -0,0 +1,122 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qqiao/webapp/v2/session"
+)
+
+var (
+	hashKey  = bytes.Repeat([]byte("h"), 32)
+	hashKey2 = bytes.Repeat([]byte("H"), 32)
+	blockKey = bytes.Repeat([]byte("b"), 32)
+)
+
+// newCookieStore creates a cookie store with the given hash keys, which must
+// be valid.
+func newCookieStore(hashKeys ...[]byte) *session.CookieStore {
+	store, err := session.NewCookieStore(hashKeys...)
+	if err != nil {
+		panic(err)
+	}
+	return store
+}
+
+func init() {
+	stores["CookieStore"] = newCookieStore(hashKey)
+	stores["EncryptedCookieStore"] = newCookieStore(hashKey).
+		WithEncryptionKeys(blockKey)
+}
+
+func TestNewCookieStore(t *testing.T) {
+	tests := map[string][][]byte{
+		"No keys":   nil,
+		"Empty key": {hashKey, {}},
+	}
+
+	for name, keys := range tests {
+		if _, err := session.NewCookieStore(keys...); !errors.Is(err,
+			session.ErrNoHashKey) {
+			t.Errorf("%s: expected ErrNoHashKey, got: %v", name, err)
+		}
+	}
+}
+
+func TestCookieStoreTampering(t *testing.T) {
+	store := newCookieStore(hashKey)
+	s := session.New(time.Hour)
+	s.Set("admin", false)
+	value := save(t, store, s)
+
+	tampered := []byte(value)
+	tampered[5] ^= 1
+	if _, err := load(store, string(tampered)); !errors.Is(err,
+		session.ErrSessionInvalid) {
+		t.Errorf("Tampered cookies should be invalid, got: %v", err)
+	}
+
+	if _, err := load(newCookieStore(hashKey2), value); !errors.Is(err,
+		session.ErrSessionInvalid) {
+		t.Errorf("Cookies signed with unknown keys should be invalid, got: %v",
+			err)
+	}
+}
+
+func TestCookieStoreKeyRotation(t *testing.T) {
+	s := session.New(time.Hour)
+	s.Set("uid", "test_user")
+	value := save(t, newCookieStore(hashKey).
+		WithEncryptionKeys(blockKey), s)
+
+	rotated := newCookieStore(hashKey2, hashKey).
+		WithEncryptionKeys(bytes.Repeat([]byte("B"), 16), blockKey)
+	loaded, err := load(rotated, value)
+	if err != nil {
+		t.Fatalf("Cookies created with old keys should be accepted: %v", err)
+	}
+	if uid := loaded.Get("uid"); uid != "test_user" {
+		t.Errorf("Expected uid test_user, got: %v", uid)
+	}
+
+	if _, err = load(newCookieStore(hashKey), save(t, rotated,
+		loaded)); err == nil {
+		t.Error("Cookies should be created with the new keys")
+	}
+}
+
+func TestCookieStoreEncryption(t *testing.T) {
+	s := session.New(time.Hour)
+	s.Set("secret", "plain text value")
+	value := save(t, newCookieStore(hashKey).
+		WithEncryptionKeys(blockKey), s)
+	if strings.Contains(value, "plain") {
+		t.Error("Encrypted cookies should not contain plain text")
+	}
+}
+
+func TestCookieStoreTooLarge(t *testing.T) {
+	s := session.New(time.Hour)
+	s.Set("data", strings.Repeat("x", 5000))
+	_, errCh := newCookieStore(hashKey).Save(t.Context(), s)
+	if err := <-errCh; !errors.Is(err, session.ErrSessionTooLarge) {
+		t.Errorf("Expected ErrSessionTooLarge, got: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+
+Package session provides server side sessions for web applications.
+
+Stores
+
+A Store persists sessions and turns them into the value of the session
+cookie. Two stores are provided:
+
+CookieStore keeps the entire session in the cookie itself. The cookie is
+signed with HMAC-SHA256 and can optionally be encrypted with AES-GCM. Both
+signing and encryption keys can be rotated by prepending a new key: the first
+key is used for new cookies, while all the keys are accepted.
+
+FirestoreStore keeps sessions in a firestore collection and only sends the
+session ID in the cookie.
+
+Middleware
+
+The handler created with NewHandler loads the session of every request into
+the request context, where it can be retrieved with FromContext. The session
+is saved before the response header is written if it has been modified.
+
+	s := session.FromContext(r.Context())
+	s.Set("uid", user.UID)
+	s.RegenerateID()
+	s.AddFlash("Welcome back!")
+
+The session ID should be regenerated whenever the privilege level of the
+user changes, e.g. at login, to prevent session fixation.
+
+Flash messages
+
+Flash messages are stored in the session until they are read with Flashes,
+which is usually done when rendering the next page.
+
+*/
+package session
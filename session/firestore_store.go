@@ -0,0 +1,208 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/qqiao/webapp/v2/datastore"
+	f "github.com/qqiao/webapp/v2/datastore/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// firestoreSession is the document a session is stored as.
+type firestoreSession struct {
+	Values  map[string]any
+	Expires time.Time
+}
+
+// FirestoreStore is a Store implementation that uses firebase firestore as
+// the underlying session storage engine. Only the session ID is sent in the
+// session cookie.
+//
+// Sessions are stored as documents keyed by their IDs. Values must be of types
+// supported by firestore.
+type FirestoreStore struct {
+	client         *firestore.Client
+	collectionName string
+}
+
+// NewFirestoreStore creates a new FirestoreStore with the given firestore
+// client and collection name to store the sessions in.
+func NewFirestoreStore(client *firestore.Client,
+	collectionName string) *FirestoreStore {
+	return &FirestoreStore{
+		client:         client,
+		collectionName: collectionName,
+	}
+}
+
+// Load loads the session with the ID given as the cookie value. Values that
+// are not session IDs generated by this package are rejected with
+// ErrSessionInvalid, without reaching firestore.
+func (s *FirestoreStore) Load(ctx context.Context, value string) (<-chan *Session,
+	<-chan error) {
+	sessionCh := make(chan *Session)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(sessionCh)
+		defer close(errCh)
+
+		if !validID(value) {
+			errCh <- ErrSessionInvalid
+			return
+		}
+
+		ds, err := s.client.Collection(s.collectionName).Doc(value).Get(ctx)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				err = ErrSessionNotFound
+			}
+			errCh <- err
+			return
+		}
+
+		var doc firestoreSession
+		if err = ds.DataTo(&doc); err != nil {
+			errCh <- err
+			return
+		}
+		if !doc.Expires.After(time.Now()) {
+			errCh <- ErrSessionNotFound
+			return
+		}
+		if doc.Values == nil {
+			doc.Values = make(map[string]any)
+		}
+		sessionCh <- &Session{
+			ID:      value,
+			Values:  doc.Values,
+			Expires: doc.Expires,
+		}
+	}()
+
+	return sessionCh, errCh
+}
+
+// Save saves the session and returns its ID as the cookie value.
+func (s *FirestoreStore) Save(ctx context.Context, session *Session) (<-chan string,
+	<-chan error) {
+	valueCh := make(chan string)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(valueCh)
+		defer close(errCh)
+
+		if _, err := s.client.Collection(s.collectionName).Doc(session.ID).
+			Set(ctx, firestoreSession{
+				Values:  session.Values,
+				Expires: session.Expires,
+			}); err != nil {
+			errCh <- err
+			return
+		}
+		valueCh <- session.ID
+	}()
+
+	return valueCh, errCh
+}
+
+// Delete deletes the session with the given ID permanently from firestore.
+func (s *FirestoreStore) Delete(ctx context.Context, id string) <-chan error {
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+
+		if _, err := s.client.Collection(s.collectionName).Doc(id).
+			Delete(ctx); err != nil {
+			errCh <- err
+		}
+	}()
+
+	return errCh
+}
+
+// purgeBatchSize is the number of expired sessions Purge deletes at a time,
+// which is kept within the limit of 500 writes per firestore commit.
+const purgeBatchSize = 500
+
+// Purge removes all the sessions that expired before or at the cutoff time.
+//
+// Expired sessions are never loaded, but they stay in firestore until they
+// are purged, so applications should call this method periodically. Sessions
+// are deleted in batches of purgeBatchSize, so any number of expired
+// sessions can be purged.
+func (s *FirestoreStore) Purge(ctx context.Context, cutoff time.Time) <-chan error {
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+
+		q := f.ApplyQuery(s.client.Collection(s.collectionName),
+			datastore.Query{
+				Limit: purgeBatchSize,
+				Filters: []datastore.Filter{
+					{
+						Path:     "Expires",
+						Operator: "<=",
+						Value:    cutoff,
+					},
+				},
+			})
+
+		for {
+			docs, err := q.Documents(ctx).GetAll()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(docs) == 0 {
+				return
+			}
+
+			bw := s.client.BulkWriter(ctx)
+			jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+			for _, ds := range docs {
+				job, err := bw.Delete(ds.Ref)
+				if err != nil {
+					bw.End()
+					errCh <- err
+					return
+				}
+				jobs = append(jobs, job)
+			}
+			bw.End()
+
+			for _, job := range jobs {
+				if _, err := job.Results(); err != nil {
+					errCh <- err
+					return
+				}
+			}
+
+			if len(docs) < purgeBatchSize {
+				return
+			}
+		}
+	}()
+
+	return errCh
+}
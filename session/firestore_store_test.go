@@ -0,0 +1,93 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/qqiao/webapp/v2/session"
+)
+
+func init() {
+	// Unlike the cookie store, the firestore store can only be tested
+	// against the emulator.
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		return
+	}
+
+	client, err := firestore.NewClient(context.Background(), "test-project")
+	if err != nil {
+		log.Fatalf("Unable to initialize firebase client. Error: %v", err)
+	}
+
+	stores["FirestoreStore"] = session.NewFirestoreStore(client,
+		"TestSessionCollection")
+}
+
+func TestFirestoreStorePurge(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "test-project")
+	if err != nil {
+		t.Fatalf("Unable to initialize firebase client: %v", err)
+	}
+	defer client.Close()
+
+	// More sessions than fit into a single firestore commit.
+	store := session.NewFirestoreStore(client, "TestSessionPurgeCollection")
+	for range 600 {
+		s := session.New(time.Hour)
+		s.Expires = time.Now().Add(-time.Hour)
+		save(t, store, s)
+	}
+	valid := session.New(time.Hour)
+	save(t, store, valid)
+
+	if err := <-store.Purge(ctx, time.Now()); err != nil {
+		t.Fatalf("Unable to purge sessions: %v", err)
+	}
+
+	docs, err := client.Collection("TestSessionPurgeCollection").
+		Documents(ctx).GetAll()
+	if err != nil {
+		t.Fatalf("Unable to list sessions: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Ref.ID != valid.ID {
+		t.Errorf("Only the valid session should be left, got %d sessions",
+			len(docs))
+	}
+}
+
+func TestFirestoreStoreInvalidID(t *testing.T) {
+	// Invalid IDs are rejected before the client is used.
+	store := session.NewFirestoreStore(nil, "TestSessionCollection")
+	for _, value := range []string{"", "a/b", "sessions/" + session.New(
+		time.Hour).ID, "not-a-session-id"} {
+		if _, err := load(store, value); !errors.Is(err,
+			session.ErrSessionInvalid) {
+			t.Errorf("Load(%q) should fail with ErrSessionInvalid, got: %v",
+				value, err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qqiao/webapp/v2/internal/httpheader"
+)
+
+// Default values used by Options.
+const (
+	DefaultCookieName = "session"
+	DefaultMaxAge     = 24 * time.Hour
+)
+
+// Options controls how the handler created with NewHandler loads and saves
+// sessions.
+type Options struct {
+	store    Store
+	name     string
+	path     string
+	domain   string
+	maxAge   time.Duration
+	secure   bool
+	sameSite http.SameSite
+}
+
+// NewOptions creates session options for the given store with the following
+// defaults: the cookie is named DefaultCookieName, is valid for the whole
+// site, is Secure, HttpOnly and SameSite=Lax, and sessions expire after
+// DefaultMaxAge.
+func NewOptions(store Store) *Options {
+	return &Options{
+		store:    store,
+		name:     DefaultCookieName,
+		path:     "/",
+		maxAge:   DefaultMaxAge,
+		secure:   true,
+		sameSite: http.SameSiteLaxMode,
+	}
+}
+
+// WithCookieName sets the name of the session cookie.
+func (o *Options) WithCookieName(name string) *Options {
+	o.name = name
+	return o
+}
+
+// WithPath sets the path of the session cookie.
+func (o *Options) WithPath(path string) *Options {
+	o.path = path
+	return o
+}
+
+// WithDomain sets the domain of the session cookie.
+func (o *Options) WithDomain(domain string) *Options {
+	o.domain = domain
+	return o
+}
+
+// WithMaxAge sets how long sessions last. Sessions are extended by maxAge
+// every time they are saved.
+func (o *Options) WithMaxAge(maxAge time.Duration) *Options {
+	o.maxAge = maxAge
+	return o
+}
+
+// WithSecure sets whether the session cookie is marked Secure. It should only
+// be disabled for development over plain HTTP.
+func (o *Options) WithSecure(secure bool) *Options {
+	o.secure = secure
+	return o
+}
+
+// WithSameSite sets the SameSite attribute of the session cookie.
+func (o *Options) WithSameSite(sameSite http.SameSite) *Options {
+	o.sameSite = sameSite
+	return o
+}
+
+// NewHandler takes a normal HTTP handler and loads the session of every
+// request into the request context, where it can be retrieved with
+// FromContext.
+//
+// A new session is created if the request has no valid session. Modified
+// sessions are saved right before the response header is written, or after h
+// returns if it writes nothing. Errors loading or saving sessions are logged.
+func NewHandler(h http.Handler, opts *Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := opts.load(r)
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, s))
+
+		sw := &responseWriter{ResponseWriter: w, save: func() {
+			opts.save(w, r, s)
+		}}
+		h.ServeHTTP(sw, r)
+		sw.saveOnce.Do(sw.save)
+	})
+}
+
+// load loads the session of the request, or creates a new one.
+func (o *Options) load(r *http.Request) *Session {
+	cookie, err := r.Cookie(o.name)
+	if err != nil {
+		return New(o.maxAge)
+	}
+
+	s, err := receive(o.store.Load(r.Context(), cookie.Value))
+	if err != nil {
+		if !errors.Is(err, ErrSessionNotFound) &&
+			!errors.Is(err, ErrSessionInvalid) {
+			log.Printf("Unable to load session: %v", err)
+		}
+		return New(o.maxAge)
+	}
+	return s
+}
+
+// save saves the session if it has been modified and sets the session cookie.
+func (o *Options) save(w http.ResponseWriter, r *http.Request, s *Session) {
+	s.mu.Lock()
+	if !s.modified {
+		s.mu.Unlock()
+		return
+	}
+	snapshot := &Session{
+		ID:      s.ID,
+		Values:  maps.Clone(s.Values),
+		Expires: time.Now().Add(o.maxAge),
+	}
+	oldID, destroyed := s.oldID, s.destroyed
+	s.modified, s.oldID = false, ""
+	s.mu.Unlock()
+
+	ctx := r.Context()
+	if oldID != "" {
+		if _, err := receive[struct{}](nil, o.store.Delete(ctx, oldID)); err != nil {
+			log.Printf("Unable to delete session: %v", err)
+		}
+	}
+
+	cookie := &http.Cookie{
+		Name:     o.name,
+		Path:     o.path,
+		Domain:   o.domain,
+		Secure:   o.secure,
+		HttpOnly: true,
+		SameSite: o.sameSite,
+	}
+	if destroyed {
+		if _, err := receive[struct{}](nil, o.store.Delete(ctx,
+			snapshot.ID)); err != nil {
+			log.Printf("Unable to delete session: %v", err)
+		}
+		cookie.MaxAge = -1
+	} else {
+		value, err := receive(o.store.Save(ctx, snapshot))
+		if err != nil {
+			log.Printf("Unable to save session: %v", err)
+			return
+		}
+		cookie.Value = value
+		cookie.Expires = snapshot.Expires
+		cookie.MaxAge = int(o.maxAge / time.Second)
+	}
+	http.SetCookie(w, cookie)
+	httpheader.AddVary(w.Header(), "Cookie")
+}
+
+// receive waits for the result of a store operation.
+func receive[T any](values <-chan T, errs <-chan error) (T, error) {
+	var zero T
+	for values != nil || errs != nil {
+		select {
+		case v, ok := <-values:
+			if ok {
+				return v, nil
+			}
+			values = nil
+		case err, ok := <-errs:
+			if ok && err != nil {
+				return zero, err
+			}
+			if !ok {
+				errs = nil
+			}
+		}
+	}
+	return zero, nil
+}
+
+// responseWriter saves the session right before the response header is
+// written.
+type responseWriter struct {
+	http.ResponseWriter
+	save     func()
+	saveOnce sync.Once
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.saveOnce.Do(w.save)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.saveOnce.Do(w.save)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	w.saveOnce.Do(w.save)
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, so that websockets keep working. The
+// session is saved before the connection is taken over, although the cookie
+// can no longer be sent.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.saveOnce.Do(w.save)
+		return hj.Hijack()
+	}
+	return nil, nil, errors.ErrUnsupported
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by
+// http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qqiao/webapp/v2/session"
+)
+
+// serve performs a request through h with the given cookie and returns the
+// recorded response.
+func serve(h http.Handler, cookie *http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// sessionCookie returns the session cookie set in the response, if any.
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == session.DefaultCookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	var handler http.HandlerFunc
+	h := session.NewHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		handler(w, r)
+	}), session.NewOptions(newCookieStore(hashKey)))
+
+	handler = func(w http.ResponseWriter, r *http.Request) {}
+	if cookie := sessionCookie(serve(h, nil)); cookie != nil {
+		t.Error("Unmodified new sessions should not be saved")
+	}
+
+	var id string
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		id = s.ID
+		s.Set("uid", "test_user")
+		s.AddFlash("Welcome")
+		w.Write([]byte("OK"))
+	}
+	cookie := sessionCookie(serve(h, nil))
+	if cookie == nil {
+		t.Fatal("Modified sessions should be saved before writing")
+	}
+	if !cookie.HttpOnly || !cookie.Secure || cookie.MaxAge <= 0 {
+		t.Errorf("Unexpected cookie: %v", cookie)
+	}
+
+	var flashes []any
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		if s.ID != id || s.Get("uid") != "test_user" {
+			t.Errorf("Session should be loaded from the cookie, got: %v",
+				s.Values)
+		}
+		flashes = s.Flashes()
+	}
+	cookie = sessionCookie(serve(h, cookie))
+	if len(flashes) != 1 || flashes[0] != "Welcome" {
+		t.Errorf("Expected the flash message, got: %v", flashes)
+	}
+
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		if flashes := session.FromContext(r.Context()).Flashes(); len(flashes) > 0 {
+			t.Errorf("Flash messages should only be read once, got: %v",
+				flashes)
+		}
+		session.FromContext(r.Context()).RegenerateID()
+	}
+	cookie = sessionCookie(serve(h, cookie))
+
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		if s.ID == id {
+			t.Error("Session ID should be regenerated")
+		}
+		if s.Get("uid") != "test_user" {
+			t.Error("Values should survive regenerating the ID")
+		}
+		s.Destroy()
+		w.WriteHeader(http.StatusNoContent)
+	}
+	if cookie = sessionCookie(serve(h, cookie)); cookie == nil ||
+		cookie.MaxAge >= 0 {
+		t.Errorf("Destroyed sessions should expire the cookie, got: %v", cookie)
+	}
+}
+
+func TestHandlerVary(t *testing.T) {
+	h := session.NewHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Vary", "Cookie")
+		session.FromContext(r.Context()).Set("uid", "test_user")
+		w.Write([]byte("OK"))
+	}), session.NewOptions(newCookieStore(hashKey)))
+
+	if got := serve(h, nil).Header().Values("Vary"); len(got) != 1 {
+		t.Errorf("Cookie should not be added to Vary again, got: %q", got)
+	}
+}
+
+func TestHandlerInvalidCookie(t *testing.T) {
+	h := session.NewHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if s := session.FromContext(r.Context()); s == nil || !s.IsNew() {
+			t.Error("Invalid cookies should result in a new session")
+		}
+	}), session.NewOptions(newCookieStore(hashKey)))
+
+	serve(h, &http.Cookie{Name: session.DefaultCookieName, Value: "invalid"})
+}
+
+// hijackRecorder is a ResponseRecorder that supports hijacking.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestHandlerHijack(t *testing.T) {
+	h := session.NewHandler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		session.FromContext(r.Context()).Set("uid", "test_user")
+		if _, _, err := http.NewResponseController(w).Hijack(); err != nil {
+			t.Errorf("Unable to hijack the connection: %v", err)
+		}
+	}), session.NewOptions(newCookieStore(hashKey)))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	if !w.hijacked {
+		t.Error("Connection should have been hijacked")
+	}
+	if sessionCookie(w.ResponseRecorder) == nil {
+		t.Error("Session should be saved before hijacking")
+	}
+}
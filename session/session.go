@@ -0,0 +1,189 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors.
+var (
+	ErrNoHashKey       = errors.New("no hash key")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionInvalid  = errors.New("session invalid")
+	ErrSessionTooLarge = errors.New("session too large")
+)
+
+// flashKey is the key flash messages are stored under in the session values.
+const flashKey = "_flash"
+
+type contextKey struct{}
+
+func init() {
+	gob.Register([]any(nil))
+	gob.Register(map[string]any(nil))
+}
+
+// Session is the session of a user.
+//
+// The fields are meant to be used by Store implementations, handlers should
+// use the methods instead, which are safe for concurrent use.
+type Session struct {
+	// ID is the unique identifier of the session.
+	ID string
+
+	// Values holds the data stored in the session.
+	Values map[string]any
+
+	// Expires is the time the session expires at.
+	Expires time.Time
+
+	mu        sync.Mutex
+	isNew     bool
+	modified  bool
+	destroyed bool
+	oldID     string
+}
+
+// New creates a new, empty session with a random ID that expires after
+// maxAge.
+func New(maxAge time.Duration) *Session {
+	return &Session{
+		ID:      newID(),
+		Values:  make(map[string]any),
+		Expires: time.Now().Add(maxAge),
+		isNew:   true,
+	}
+}
+
+// FromContext returns the session stored in the context by the handler
+// created with NewHandler, or nil if there is none.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
+
+// IsNew reports whether the session has been created for this request.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.isNew
+}
+
+// Get returns the value stored under key, or nil if there is none.
+func (s *Session) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Values[key]
+}
+
+// Set stores value under key.
+//
+// Values must be supported by the store, e.g. CookieStore requires custom
+// types to be registered with gob.Register.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, has := s.Values[key]; has {
+		delete(s.Values, key)
+		s.modified = true
+	}
+}
+
+// AddFlash adds a flash message to the session.
+func (s *Session) AddFlash(message any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flashes, _ := s.Values[flashKey].([]any)
+	s.Values[flashKey] = append(flashes, message)
+	s.modified = true
+}
+
+// Flashes returns all the flash messages in the session and removes them.
+func (s *Session) Flashes() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flashes, has := s.Values[flashKey].([]any)
+	if has {
+		delete(s.Values, flashKey)
+		s.modified = true
+	}
+	return flashes
+}
+
+// RegenerateID gives the session a new ID while keeping its values. The
+// session under the old ID is deleted from the store when the session is
+// saved.
+func (s *Session) RegenerateID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.ID
+	}
+	s.ID = newID()
+	s.modified = true
+}
+
+// Destroy removes all values from the session, deletes it from the store and
+// expires the session cookie, e.g. at logout.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Values = make(map[string]any)
+	s.destroyed = true
+	s.modified = true
+}
+
+// idLength is the length of the session IDs generated by newID in bytes,
+// before encoding.
+const idLength = 32
+
+// newID generates a random session ID.
+func newID() string {
+	b := make([]byte, idLength)
+	// crypto/rand.Read never returns an error.
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// validID reports whether id has the format of the session IDs generated by
+// newID, so that arbitrary cookie values, e.g. ones containing a slash, are
+// never used as document paths.
+func validID(id string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil && len(b) == idLength
+}
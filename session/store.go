@@ -0,0 +1,39 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import "context"
+
+// Store persists sessions. This interface defines the common operations of
+// all the session storage engines.
+//
+// Depending on where sessions are stored, there could be multiple
+// implementations of the Store interface.
+type Store interface {
+	// Load loads the session referred to by the value of the session
+	// cookie.
+	//
+	// This method returns ErrSessionNotFound if the session does not exist or
+	// has expired, and ErrSessionInvalid if the cookie value cannot be
+	// decoded or verified.
+	Load(ctx context.Context, value string) (<-chan *Session, <-chan error)
+
+	// Save saves the session and returns the value of the session cookie
+	// that refers to it.
+	Save(ctx context.Context, session *Session) (<-chan string, <-chan error)
+
+	// Delete deletes the session with the given ID.
+	Delete(ctx context.Context, id string) <-chan error
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qqiao/webapp/v2/session"
+)
+
+type testFunc func(session.Store) func(*testing.T)
+
+var stores = make(map[string]session.Store)
+var tests = map[string]testFunc{
+	"SaveLoad": testSaveLoad,
+	"Expired":  testExpired,
+	"Invalid":  testInvalid,
+}
+
+func TestSuite(t *testing.T) {
+	for sName, store := range stores {
+		t.Run(sName, func(t *testing.T) {
+			for tName, test := range tests {
+				t.Run(tName, test(store))
+			}
+		})
+	}
+}
+
+// save saves s in store and returns the cookie value.
+func save(t *testing.T, store session.Store, s *session.Session) string {
+	t.Helper()
+
+	valueCh, errCh := store.Save(context.Background(), s)
+	select {
+	case err := <-errCh:
+		t.Fatalf("Error saving session: %v", err)
+	case value := <-valueCh:
+		return value
+	}
+	return ""
+}
+
+// load loads the session referred to by value from store.
+func load(store session.Store, value string) (*session.Session, error) {
+	sessionCh, errCh := store.Load(context.Background(), value)
+	select {
+	case err := <-errCh:
+		return nil, err
+	case s := <-sessionCh:
+		return s, nil
+	}
+}
+
+func testSaveLoad(store session.Store) func(*testing.T) {
+	return func(t *testing.T) {
+		s := session.New(time.Hour)
+		s.Set("uid", "test_user")
+		value := save(t, store, s)
+
+		loaded, err := load(store, value)
+		if err != nil {
+			t.Fatalf("Error loading session: %v", err)
+		}
+		if loaded.ID != s.ID {
+			t.Errorf("Expected ID %s, got: %s", s.ID, loaded.ID)
+		}
+		if uid := loaded.Get("uid"); uid != "test_user" {
+			t.Errorf("Expected uid test_user, got: %v", uid)
+		}
+		if loaded.IsNew() {
+			t.Error("Loaded sessions should not be new")
+		}
+	}
+}
+
+func testExpired(store session.Store) func(*testing.T) {
+	return func(t *testing.T) {
+		s := session.New(time.Hour)
+		s.Expires = time.Now().Add(-time.Minute)
+		value := save(t, store, s)
+
+		if _, err := load(store, value); !errors.Is(err,
+			session.ErrSessionNotFound) {
+			t.Errorf("Expected ErrSessionNotFound, got: %v", err)
+		}
+	}
+}
+
+func testInvalid(store session.Store) func(*testing.T) {
+	return func(t *testing.T) {
+		if _, err := load(store, "invalid"); err == nil {
+			t.Error("Loading an invalid value should fail")
+		}
+	}
+}
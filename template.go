@@ -0,0 +1,439 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Errors.
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+)
+
+// TemplatePrecompiler is implemented by template caches that know all the
+// templates they serve, such as TemplateLoader and TemplateRegistry.
+type TemplatePrecompiler interface {
+	// Precompile parses all the known templates and caches them. Errors of all
+	// the templates that fail to parse are joined into the returned error.
+	Precompile() error
+}
+
+// LoadTemplate loads the template from the given path.
+//
+// It works the same way as GetTemplate, except that parse errors are returned
+// instead of causing a panic.
+func LoadTemplate(path string, skipCache bool) (*template.Template, error) {
+	return defaultTemplateLoader.Load(path, skipCache)
+}
+
+// RegisterTemplates registers templates loaded with GetTemplate or
+// LoadTemplate to be parsed by PrecompileTemplates.
+func RegisterTemplates(paths ...string) {
+	defaultTemplateLoader.Register(paths...)
+}
+
+// PrecompileTemplates parses all the templates registered with
+// RegisterTemplates, as well as all the templates known to the given
+// precompilers, and caches them.
+//
+// It is meant to be called at startup, so that a broken template fails the
+// deployment instead of the first request using it. Errors of all the
+// templates that fail to parse are joined into the returned error.
+func PrecompileTemplates(precompilers ...TemplatePrecompiler) error {
+	errs := []error{defaultTemplateLoader.Precompile()}
+	for _, p := range precompilers {
+		errs = append(errs, p.Precompile())
+	}
+	return errors.Join(errs...)
+}
+
+// TemplateLoader loads templates from a file system and caches the parsed
+// results, so that the same template would not be parsed over and over again.
+//
+// Any fs.FS can be used, e.g. an embed.FS for templates embedded into the
+// binary, os.DirFS for templates on disk or fstest.MapFS in tests.
+type TemplateLoader struct {
+	fsys  fs.FS
+	cache *sync.Map
+
+	mu    sync.Mutex
+	paths []string
+}
+
+// NewTemplateLoader creates a template loader that loads templates from fsys.
+func NewTemplateLoader(fsys fs.FS) *TemplateLoader {
+	return &TemplateLoader{
+		fsys:  fsys,
+		cache: &sync.Map{},
+	}
+}
+
+// Get loads the template from the given path in the file system of the
+// loader.
+//
+// The function caches the loaded template so that the same template would not
+// be parsed over and over again unless skipCache is set to true.
+//
+// Please note this method panics if the template cannot be parsed in any way,
+// please use Load to handle the error instead.
+func (l *TemplateLoader) Get(path string, skipCache bool) *template.Template {
+	return mustTemplate(l.Load(path, skipCache))
+}
+
+// Load loads the template from the given path in the file system of the
+// loader.
+//
+// It works the same way as Get, except that parse errors are returned instead
+// of causing a panic. Failed results are not cached.
+func (l *TemplateLoader) Load(path string, skipCache bool) (*template.Template,
+	error) {
+	entry, has := l.cache.Load(path)
+	if !has || skipCache {
+		tmpl, err := parseTemplateFiles(l.fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		entry = &templateEntry{
+			template: tmpl,
+			files:    []string{path},
+		}
+		l.cache.Store(path, entry)
+	}
+	return entry.(*templateEntry).template, nil
+}
+
+// Register registers templates to be parsed by Precompile.
+func (l *TemplateLoader) Register(paths ...string) *TemplateLoader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.paths = append(l.paths, paths...)
+	return l
+}
+
+// Precompile parses all the registered templates and caches them. Errors of
+// all the templates that fail to parse are joined into the returned error.
+func (l *TemplateLoader) Precompile() error {
+	l.mu.Lock()
+	paths := append([]string(nil), l.paths...)
+	l.mu.Unlock()
+
+	errs := make([]error, 0)
+	for _, path := range paths {
+		if _, err := l.Load(path, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reload parses all the cached templates affected by the change again. Cached
+// templates are only replaced if they parse successfully.
+func (l *TemplateLoader) reload(change templateChange) map[string]error {
+	errs := make(map[string]error)
+	l.cache.Range(func(key, value any) bool {
+		path, entry := key.(string), value.(*templateEntry)
+		if !entry.dependsOn(change) {
+			return true
+		}
+
+		tmpl, err := parseTemplateFiles(l.fsys, path)
+		if err == nil {
+			l.cache.Store(path, &templateEntry{
+				template: tmpl,
+				files:    []string{path},
+			})
+		}
+		markReloaded(entry.template, path, err)
+		errs[path] = err
+		return true
+	})
+	return errs
+}
+
+// TemplateRegistry builds pages out of a shared layout, a set of partials and
+// a page specific file.
+//
+// For each page, the layout is parsed first, followed by the partials and then
+// the page file. Since later definitions replace earlier ones, pages can
+// override any {{block}} or {{define}} of the layout and the partials.
+//
+// Just like GetTemplate, parsed pages are cached so that the same page would
+// not be parsed over and over again.
+type TemplateRegistry struct {
+	fsys     fs.FS
+	layout   string
+	partials string
+
+	mu    sync.RWMutex
+	pages map[string]string
+
+	cache sync.Map
+}
+
+// NewTemplateRegistry creates a template registry with the given layout file
+// and partials glob pattern. The syntax of partials is the same as in
+// filepath.Match, an empty pattern means there are no partials.
+//
+// If layout is empty, pages are executed directly.
+//
+// Files are read from the OS file system, WithFS can be used to read them from
+// any other fs.FS instead.
+func NewTemplateRegistry(layout string, partials string) *TemplateRegistry {
+	return &TemplateRegistry{
+		fsys:     osFS{},
+		layout:   layout,
+		partials: partials,
+		pages:    make(map[string]string),
+	}
+}
+
+// WithFS sets the file system the layout, partials and pages are read from.
+// All paths are then interpreted as fs.FS paths, and the syntax of partials
+// becomes the same as in path.Match.
+func (r *TemplateRegistry) WithFS(fsys fs.FS) *TemplateRegistry {
+	r.fsys = fsys
+	r.cache.Clear()
+	return r
+}
+
+// Register registers the page file at path under the given name. Registering
+// a name again replaces the page and invalidates its cached template.
+func (r *TemplateRegistry) Register(name string, path string) *TemplateRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pages[name] = path
+	r.cache.Delete(name)
+	return r
+}
+
+// Get returns the template set of the named page.
+//
+// The function caches the parsed template set so that the same page would not
+// be parsed over and over again unless skipCache is set to true.
+//
+// Please note this method panics if the page is not registered or if parsing
+// fails in any way, please use Load to handle the error instead.
+func (r *TemplateRegistry) Get(name string, skipCache bool) *template.Template {
+	return mustTemplate(r.Load(name, skipCache))
+}
+
+// Load returns the template set of the named page.
+//
+// It works the same way as Get, except that errors are returned instead of
+// causing a panic. ErrTemplateNotFound is returned if the page is not
+// registered. Failed results are not cached.
+func (r *TemplateRegistry) Load(name string, skipCache bool) (*template.Template,
+	error) {
+	entry, has := r.cache.Load(name)
+	if !has || skipCache {
+		tmpl, files, err := r.parse(name)
+		if err != nil {
+			return nil, err
+		}
+		entry = &templateEntry{
+			template: tmpl,
+			files:    files,
+		}
+		r.cache.Store(name, entry)
+	}
+	return entry.(*templateEntry).template, nil
+}
+
+// Precompile parses all the registered pages and caches them. Errors of all
+// the pages that fail to parse are joined into the returned error.
+func (r *TemplateRegistry) Precompile() error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.pages))
+	for name := range r.pages {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	errs := make([]error, 0)
+	for _, name := range names {
+		if _, err := r.Load(name, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Render executes the named page with the given data and writes the output to
+// w. Functions registered with RegisterRequestTemplateFuncs are bound to req.
+//
+// An error is returned if the page cannot be loaded or executed.
+func (r *TemplateRegistry) Render(w io.Writer, req *http.Request, name string,
+	data any) error {
+	tmpl, err := r.Load(name, false)
+	if err != nil {
+		return err
+	}
+	return ExecuteTemplate(w, req, tmpl, data)
+}
+
+// reload parses all the cached pages affected by the change again. A change
+// to any file matching the partials pattern affects all pages, since the file
+// might be a new partial. Cached pages are only replaced if they parse
+// successfully.
+func (r *TemplateRegistry) reload(change templateChange) map[string]error {
+	partial := r.partials != "" && change.matchesPattern(r.partials)
+
+	errs := make(map[string]error)
+	r.cache.Range(func(key, value any) bool {
+		name, entry := key.(string), value.(*templateEntry)
+		if !partial && !entry.dependsOn(change) {
+			return true
+		}
+
+		tmpl, files, err := r.parse(name)
+		if err == nil {
+			r.cache.Store(name, &templateEntry{template: tmpl, files: files})
+		}
+		markReloaded(entry.template, name, err)
+		errs[name] = err
+		return true
+	})
+	return errs
+}
+
+// parse parses the template set of the named page. The files the set is
+// parsed from are returned along with it.
+func (r *TemplateRegistry) parse(name string) (*template.Template, []string,
+	error) {
+	r.mu.RLock()
+	page, has := r.pages[name]
+	r.mu.RUnlock()
+	if !has {
+		return nil, nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	files := make([]string, 0)
+	if r.layout != "" {
+		files = append(files, r.layout)
+	}
+	if r.partials != "" {
+		partials, err := fs.Glob(r.fsys, r.partials)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, partials...)
+	}
+	files = append(files, page)
+
+	tmpl, err := parseTemplateFiles(r.fsys, files...)
+	return tmpl, files, err
+}
+
+// templateEntry is a cached template along with the files it is parsed from.
+type templateEntry struct {
+	template *template.Template
+	files    []string
+}
+
+// templateError is the panic value of Get, so that template errors can be
+// told apart from other panics.
+type templateError struct {
+	err error
+}
+
+func (e *templateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *templateError) Unwrap() error {
+	return e.err
+}
+
+// mustTemplate works the same way as template.Must, except that it panics
+// with a templateError.
+func mustTemplate(tmpl *template.Template, err error) *template.Template {
+	if err != nil {
+		panic(&templateError{err: err})
+	}
+	return tmpl
+}
+
+// dependsOn reports whether the template is parsed from the changed file.
+func (e *templateEntry) dependsOn(change templateChange) bool {
+	for _, file := range e.files {
+		if change.matches(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTemplateFiles parses the given files from fsys into a single template
+// set. It works the same way as template.ParseFiles: the set is named after
+// the first file, and each file is associated with the set under its base
+// name.
+//
+// Unlike template.ParseFS, the file names are never treated as patterns.
+func parseTemplateFiles(fsys fs.FS, files ...string) (*template.Template,
+	error) {
+	var t *template.Template
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Base(filepath.ToSlash(file))
+		var tmpl *template.Template
+		if t == nil {
+			t = newTemplate(name)
+		}
+		if name == t.Name() {
+			tmpl = t
+		} else {
+			tmpl = t.New(name)
+		}
+		if _, err = tmpl.Parse(string(b)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// osFS is a file system that opens paths directly from the OS. Unlike
+// os.DirFS, it accepts both absolute and relative paths, which is what
+// GetTemplate has always done.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// RequestTemplateFuncs returns template functions that are bound to the given
+// request, e.g. functions that depend on the negotiated locale.
+type RequestTemplateFuncs func(r *http.Request) template.FuncMap
+
+var (
+	templateFuncsMu      sync.RWMutex
+	templateFuncs        = template.FuncMap{}
+	requestTemplateFuncs []RequestTemplateFuncs
+)
+
+// RegisterTemplateFuncs adds the given functions to all templates loaded by
+// this package.
+//
+// Functions must be registered before the templates using them are parsed,
+// usually in an init function or at the start of the program. Registering a
+// function with an existing name replaces the previous one.
+func RegisterTemplateFuncs(funcs template.FuncMap) {
+	templateFuncsMu.Lock()
+	defer templateFuncsMu.Unlock()
+
+	for name, fn := range funcs {
+		templateFuncs[name] = fn
+	}
+}
+
+// RegisterRequestTemplateFuncs registers template functions that are bound to
+// the request being served.
+//
+// The provider is called once at registration with a blank request, the
+// functions it returns are made available to all templates loaded by this
+// package so that templates using them can be parsed. When a template is
+// executed with ExecuteTemplate, the provider is called again with the actual
+// request and the functions it returns replace the placeholders.
+//
+// Just like RegisterTemplateFuncs, this function must be called before the
+// templates using the functions are parsed.
+func RegisterRequestTemplateFuncs(provider RequestTemplateFuncs) {
+	placeholders := provider(blankRequest())
+
+	templateFuncsMu.Lock()
+	defer templateFuncsMu.Unlock()
+
+	for name, fn := range placeholders {
+		templateFuncs[name] = fn
+	}
+	requestTemplateFuncs = append(requestTemplateFuncs, provider)
+}
+
+// ExecuteTemplate applies the template to the data object and writes the
+// output to w, with all the functions registered with
+// RegisterRequestTemplateFuncs bound to the given request.
+//
+// The template is always cloned before it is executed, even if no request
+// template functions are registered yet, so that functions registered later
+// can still be bound. The template itself must not be executed directly, as
+// html/template does not allow cloning executed templates.
+//
+// For requests served by the handler of a TemplateWatcher, ExecuteTemplate
+// panics if the template failed to reload, so that the handler renders the
+// reload error instead of the last good version.
+func ExecuteTemplate(w io.Writer, r *http.Request, tmpl *template.Template,
+	data any) error {
+	checkReloaded(r, tmpl)
+	bound, err := bindRequest(tmpl, r)
+	if err != nil {
+		return err
+	}
+	return bound.Execute(w, data)
+}
+
+// bindRequest returns a copy of the template with the request template
+// functions bound to r.
+func bindRequest(tmpl *template.Template, r *http.Request) (*template.Template,
+	error) {
+	templateFuncsMu.RLock()
+	providers := requestTemplateFuncs
+	templateFuncsMu.RUnlock()
+
+	bound, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		bound.Funcs(provider(r))
+	}
+	return bound, nil
+}
+
+// newTemplate creates a new template with the given name and all the
+// registered template functions.
+func newTemplate(name string) *template.Template {
+	templateFuncsMu.RLock()
+	defer templateFuncsMu.RUnlock()
+
+	return template.New(name).Funcs(templateFuncs)
+}
+
+// blankRequest returns an empty request that providers of request template
+// functions can safely inspect.
+func blankRequest() *http.Request {
+	return &http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/"},
+		Header: http.Header{},
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	RegisterTemplateFuncs(template.FuncMap{"upper": strings.ToUpper})
+	RegisterRequestTemplateFuncs(func(r *http.Request) template.FuncMap {
+		return template.FuncMap{
+			"requestPath": func() string { return r.URL.Path },
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "funcs.html")
+	if err := os.WriteFile(path,
+		[]byte(`{{upper "a"}}|{{requestPath}}`), 0600); err != nil {
+		t.Fatalf("Unable to write template: %v", err)
+	}
+	tmpl := GetTemplate(path, false)
+
+	for _, p := range []string{"/one", "/two"} {
+		var buf bytes.Buffer
+		r := httptest.NewRequest("GET", p, nil)
+		if err := ExecuteTemplate(&buf, r, tmpl, nil); err != nil {
+			t.Fatalf("Unable to execute template: %v", err)
+		}
+		if expected := "A|" + p; buf.String() != expected {
+			t.Errorf("Expected %q, got: %q", expected, buf.String())
+		}
+	}
+}
+
+func TestExecuteTemplateLateRegistration(t *testing.T) {
+	// Start without any request template functions, as in an application
+	// that registers them after templates have been executed.
+	templateFuncsMu.Lock()
+	providers := requestTemplateFuncs
+	requestTemplateFuncs = nil
+	templateFuncsMu.Unlock()
+	t.Cleanup(func() {
+		templateFuncsMu.Lock()
+		requestTemplateFuncs = providers
+		templateFuncsMu.Unlock()
+	})
+
+	dir := writeTemplates(t, map[string]string{"page.html": `<p>page</p>`})
+	tmpl, err := LoadTemplate(filepath.Join(dir, "page.html"), false)
+	if err != nil {
+		t.Fatalf("Unable to load template: %v", err)
+	}
+
+	execute := func() {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := ExecuteTemplate(&buf, httptest.NewRequest("GET", "/", nil),
+			tmpl, nil); err != nil {
+			t.Fatalf("Unable to execute template: %v", err)
+		}
+	}
+
+	execute()
+	RegisterRequestTemplateFuncs(cspNonceFuncs)
+	execute()
+	execute()
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("Unable to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Unable to write template: %v", err)
+		}
+	}
+	return dir
+}
+
+var registryTemplates = map[string]string{
+	"layout.html": `<title>{{block "title" .}}Default{{end}}</title>` +
+		`{{template "nav" .}}{{block "content" .}}{{end}}`,
+	"partials/nav.html":  `{{define "nav"}}<nav>{{.}}</nav>{{end}}`,
+	"pages/home.html":    `{{define "content"}}home{{end}}`,
+	"pages/about.html":   `{{define "title"}}About{{end}}{{define "content"}}about{{end}}`,
+	"pages/broken.html":  `{{define "content"}}{{.Missing}`,
+	"pages/partial.html": `{{define "nav"}}custom{{end}}`,
+}
+
+func TestTemplateRegistry(t *testing.T) {
+	dir := writeTemplates(t, registryTemplates)
+	registry := NewTemplateRegistry(filepath.Join(dir, "layout.html"),
+		filepath.Join(dir, "partials", "*.html"))
+	for _, page := range []string{"home", "about", "broken", "partial"} {
+		registry.Register(page, filepath.Join(dir, "pages", page+".html"))
+	}
+
+	tests := map[string]string{
+		"home":    "<title>Default</title><nav>x</nav>home",
+		"about":   "<title>About</title><nav>x</nav>about",
+		"partial": "<title>Default</title>custom",
+	}
+	for page, expected := range tests {
+		var buf bytes.Buffer
+		if err := registry.Render(&buf, httptest.NewRequest("GET", "/", nil),
+			page, "x"); err != nil {
+			t.Errorf("Unable to render %s: %v", page, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("Expected %q for %s, got: %q", expected, page, buf.String())
+		}
+	}
+
+	if registry.Get("home", false) != registry.Get("home", false) {
+		t.Error("Template set should be cached")
+	}
+	if registry.Get("home", false) == registry.Get("home", true) {
+		t.Error("Template set should be parsed again when skipping cache")
+	}
+
+	t.Run("Broken pages should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Get should panic on a broken page")
+			}
+		}()
+		registry.Get("broken", false)
+	})
+
+	t.Run("Unknown pages should not be found", func(t *testing.T) {
+		if _, _, err := registry.parse("unknown"); !errors.Is(err,
+			ErrTemplateNotFound) {
+			t.Errorf("Expecting ErrTemplateNotFound, got: %v", err)
+		}
+	})
+}
+
+func mapFS(files map[string]string) fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestTemplateLoader(t *testing.T) {
+	loader := NewTemplateLoader(mapFS(map[string]string{
+		"pages/home.html": `home {{.}}`,
+		"broken.html":     `{{.Missing}`,
+	}))
+
+	var buf bytes.Buffer
+	tmpl := loader.Get("pages/home.html", false)
+	if err := tmpl.Execute(&buf, "x"); err != nil {
+		t.Fatalf("Unable to execute template: %v", err)
+	}
+	if buf.String() != "home x" {
+		t.Errorf("Expected %q, got: %q", "home x", buf.String())
+	}
+	if tmpl.Name() != "home.html" {
+		t.Errorf("Template should be named after the file, got: %q", tmpl.Name())
+	}
+	if loader.Get("pages/home.html", false) != tmpl {
+		t.Error("Template should be cached")
+	}
+
+	for _, path := range []string{"broken.html", "missing.html"} {
+		t.Run(path+" should panic", func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Get(%q) should panic", path)
+				}
+			}()
+			loader.Get(path, false)
+		})
+	}
+}
+
+func TestTemplateRegistryWithFS(t *testing.T) {
+	registry := NewTemplateRegistry("layout.html", "partials/*.html").
+		WithFS(mapFS(registryTemplates)).
+		Register("about", "pages/about.html")
+
+	var buf bytes.Buffer
+	if err := registry.Render(&buf, httptest.NewRequest("GET", "/", nil),
+		"about", "x"); err != nil {
+		t.Fatalf("Unable to render about: %v", err)
+	}
+	if expected := "<title>About</title><nav>x</nav>about"; buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"good.html":   "good",
+		"broken.html": "{{.Missing}",
+	})
+
+	if _, err := LoadTemplate(filepath.Join(dir, "good.html"), false); err != nil {
+		t.Errorf("Unable to load template: %v", err)
+	}
+	if _, err := LoadTemplate(filepath.Join(dir, "broken.html"), false); err == nil {
+		t.Error("LoadTemplate should return an error for broken templates")
+	}
+	if _, err := LoadTemplate(filepath.Join(dir, "missing.html"), false); err == nil {
+		t.Error("LoadTemplate should return an error for missing templates")
+	}
+}
+
+func TestPrecompileTemplates(t *testing.T) {
+	fsys := mapFS(registryTemplates)
+	fsys["broken.html"] = &fstest.MapFile{Data: []byte("{{.Missing}")}
+	fsys["good.html"] = &fstest.MapFile{Data: []byte("good")}
+
+	loader := NewTemplateLoader(fsys).Register("good.html", "broken.html")
+	registry := NewTemplateRegistry("layout.html", "partials/*.html").
+		WithFS(fsys).
+		Register("home", "pages/home.html").
+		Register("broken", "pages/broken.html").
+		Register("missing", "pages/missing.html")
+
+	err := PrecompileTemplates(loader, registry)
+	if err == nil {
+		t.Fatal("PrecompileTemplates should report broken templates")
+	}
+	for _, name := range []string{"broken.html", "broken", "missing"} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Errorf("Error should mention %s, got: %v", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "home:") ||
+		strings.Contains(err.Error(), "good.html:") {
+		t.Errorf("Error should not mention good templates, got: %v", err)
+	}
+
+	if err = PrecompileTemplates(NewTemplateLoader(fsys).
+		Register("good.html")); err != nil {
+		t.Errorf("PrecompileTemplates should succeed, got: %v", err)
+	}
+}
+
+func TestTemplateRegistryRenderErrors(t *testing.T) {
+	registry := NewTemplateRegistry("", "").WithFS(mapFS(registryTemplates)).
+		Register("broken", "pages/broken.html")
+
+	var buf bytes.Buffer
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := registry.Render(&buf, r, "broken", nil); err == nil {
+		t.Error("Render should return an error for broken pages")
+	}
+	if err := registry.Render(&buf, r, "unknown", nil); !errors.Is(err,
+		ErrTemplateNotFound) {
+		t.Errorf("Expecting ErrTemplateNotFound, got: %v", err)
+	}
+}
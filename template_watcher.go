@@ -0,0 +1,351 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// templateReloader is implemented by everything that caches templates which
+// can be reloaded by a TemplateWatcher.
+type templateReloader interface {
+	reload(change templateChange) map[string]error
+}
+
+// templateChange describes a changed file in terms of the paths used by a
+// template cache.
+type templateChange struct {
+	// osPaths reports whether the cache uses OS paths rather than fs.FS
+	// paths.
+	osPaths bool
+
+	// path is the absolute path of the changed file if osPaths is true, its
+	// fs.FS path otherwise.
+	path string
+}
+
+// matches reports whether file refers to the changed file.
+func (c templateChange) matches(file string) bool {
+	if c.osPaths {
+		abs, err := filepath.Abs(file)
+		return err == nil && abs == c.path
+	}
+	return path.Clean(file) == c.path
+}
+
+// matchesPattern reports whether the changed file matches pattern.
+func (c templateChange) matchesPattern(pattern string) bool {
+	if c.osPaths {
+		abs, err := filepath.Abs(pattern)
+		if err != nil {
+			return false
+		}
+		matched, _ := filepath.Match(abs, c.path)
+		return matched
+	}
+	matched, _ := path.Match(pattern, c.path)
+	return matched
+}
+
+// TemplateWatcher watches template directories on disk and reloads the
+// affected templates whenever a file changes. It is meant to be used in
+// development, so that template edits show up without restarting the
+// process.
+//
+// Only the cached templates that are parsed from the changed file are
+// reloaded. If a template fails to parse, the last good version keeps being
+// served, and the error is logged and reported by Errors until the template
+// is fixed. Requests served by Handler that execute the template get an error
+// page instead.
+type TemplateWatcher struct {
+	dir      string
+	osPaths  bool
+	reloader templateReloader
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+// WatchTemplates watches the given directories and reloads templates loaded
+// with GetTemplate when files in them change.
+func WatchTemplates(dirs ...string) (*TemplateWatcher, error) {
+	return newTemplateWatcher(defaultTemplateLoader, true, dirs...)
+}
+
+// Watch watches the directory dir and reloads the templates of the loader
+// when files in it change. The file system of the loader must be rooted at
+// dir, e.g. os.DirFS(dir).
+func (l *TemplateLoader) Watch(dir string) (*TemplateWatcher, error) {
+	_, osPaths := l.fsys.(osFS)
+	return newTemplateWatcher(l, osPaths, dir)
+}
+
+// Watch watches the directory dir and reloads the pages of the registry when
+// files in it change. If the registry reads files from an fs.FS set with
+// WithFS, the file system must be rooted at dir, e.g. os.DirFS(dir).
+func (r *TemplateRegistry) Watch(dir string) (*TemplateWatcher, error) {
+	_, osPaths := r.fsys.(osFS)
+	return newTemplateWatcher(r, osPaths, dir)
+}
+
+func newTemplateWatcher(reloader templateReloader, osPaths bool,
+	dirs ...string) (*TemplateWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &TemplateWatcher{
+		osPaths:  osPaths,
+		reloader: reloader,
+		watcher:  watcher,
+		done:     make(chan struct{}),
+		errs:     make(map[string]error),
+	}
+	if !osPaths {
+		// Caches with fs.FS paths are always rooted at a single directory.
+		w.dir = dirs[0]
+	}
+
+	for _, dir := range dirs {
+		if err = w.add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching for changes.
+func (w *TemplateWatcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+// Errors returns the errors of all the templates that failed to reload and
+// have not been fixed since.
+func (w *TemplateWatcher) Errors() []error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keys := make([]string, 0, len(w.errs))
+	for key := range w.errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := make([]error, 0, len(keys))
+	for _, key := range keys {
+		errs = append(errs, w.errs[key])
+	}
+	return errs
+}
+
+// Handler takes a normal HTTP handler and renders a readable error page
+// instead of the response when a template it uses fails to parse, i.e. when
+// GetTemplate or Get fail to load a template, or when ExecuteTemplate is
+// called with a template that failed to reload. Requests that do not use the
+// broken templates are not affected.
+//
+// Other panics are passed on, as are template errors raised after the
+// response header has been written, since the response cannot be replaced
+// any more.
+func (w *TemplateWatcher) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: rw}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			err, ok := v.(*templateError)
+			if !ok || sw.status != 0 {
+				panic(v)
+			}
+			renderTemplateErrors(rw, []error{err})
+		}()
+		h.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(),
+			templateWatcherContextKey{}, w)))
+	})
+}
+
+type templateWatcherContextKey struct{}
+
+// staleTemplates maps the cached templates that failed to reload, and are
+// still served as the last good version, to their reload errors.
+var staleTemplates sync.Map
+
+// markReloaded records the result of reloading the cached template tmpl under
+// the given key.
+func markReloaded(tmpl *template.Template, key string, err error) {
+	if err != nil {
+		staleTemplates.Store(tmpl, fmt.Errorf("%s: %w", key, err))
+	} else {
+		staleTemplates.Delete(tmpl)
+	}
+}
+
+// checkReloaded panics with the reload error of tmpl if it failed to reload
+// and the request is served by the handler of a TemplateWatcher.
+func checkReloaded(r *http.Request, tmpl *template.Template) {
+	if r.Context().Value(templateWatcherContextKey{}) == nil {
+		return
+	}
+	if err, stale := staleTemplates.Load(tmpl); stale {
+		panic(&templateError{err: err.(error)})
+	}
+}
+
+// add watches dir and all of its sub-directories.
+func (w *TemplateWatcher) add(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return w.watcher.Add(p)
+		}
+		return nil
+	})
+}
+
+// templateReloadDelay is how long the watcher waits for a burst of events to
+// settle before reloading, so that a file is not parsed halfway through being
+// written.
+const templateReloadDelay = 100 * time.Millisecond
+
+func (w *TemplateWatcher) run() {
+	defer close(w.done)
+
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(templateReloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.handle(event) {
+				pending[event.Name] = struct{}{}
+				timer.Reset(templateReloadDelay)
+			}
+		case <-timer.C:
+			for name := range pending {
+				w.reload(name)
+			}
+			clear(pending)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Error watching templates: %v", err)
+		}
+	}
+}
+
+// handle handles a file system event and reports whether the file in question
+// needs to be reloaded.
+func (w *TemplateWatcher) handle(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
+		!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err = w.add(event.Name); err != nil {
+				log.Printf("Unable to watch %s: %v", event.Name, err)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// reload reloads all the templates affected by the named file.
+func (w *TemplateWatcher) reload(name string) {
+	change, ok := w.change(name)
+	if !ok {
+		return
+	}
+
+	errs := w.reloader.reload(change)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, err := range errs {
+		if err != nil {
+			w.errs[key] = fmt.Errorf("%s: %w", key, err)
+			log.Printf("Unable to reload template: %v", w.errs[key])
+		} else {
+			delete(w.errs, key)
+		}
+	}
+}
+
+// change converts the name of a changed file into a templateChange.
+func (w *TemplateWatcher) change(name string) (templateChange, bool) {
+	if w.osPaths {
+		abs, err := filepath.Abs(name)
+		return templateChange{osPaths: true, path: abs}, err == nil
+	}
+
+	rel, err := filepath.Rel(w.dir, name)
+	if err != nil {
+		return templateChange{}, false
+	}
+	return templateChange{path: filepath.ToSlash(rel)}, true
+}
+
+var templateErrorsPage = template.Must(template.New("errors").Parse(
+	`<!DOCTYPE html>
+<html>
+<head><title>Template Error</title></head>
+<body>
+<h1>Template Error</h1>
+{{range .}}<pre>{{.}}</pre>
+{{end}}</body>
+</html>
+`))
+
+// renderTemplateErrors writes an error page listing errs to w.
+func renderTemplateErrors(w http.ResponseWriter, errs []error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	if err := templateErrorsPage.Execute(w, errs); err != nil {
+		log.Printf("Unable to render template errors: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Qian Qiao
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webapp
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func render(t *testing.T, tmpl *template.Template) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		t.Fatalf("Unable to execute template: %v", err)
+	}
+	return buf.String()
+}
+
+// eventually polls condition until it holds or the timeout expires.
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestTemplateWatcher(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"pages/a.html": "a1",
+		"pages/b.html": "b1",
+	})
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content),
+			0600); err != nil {
+			t.Fatalf("Unable to write template: %v", err)
+		}
+	}
+
+	loader := NewTemplateLoader(os.DirFS(dir))
+	watcher, err := loader.Watch(dir)
+	if err != nil {
+		t.Fatalf("Unable to watch templates: %v", err)
+	}
+	defer watcher.Close()
+
+	b := loader.Get("pages/b.html", false)
+	loader.Get("pages/a.html", false)
+
+	t.Run("Changed templates should be reloaded", func(t *testing.T) {
+		write("pages/a.html", "a2")
+		if !eventually(func() bool {
+			return render(t, loader.Get("pages/a.html", false)) == "a2"
+		}) {
+			t.Error("Template should have been reloaded")
+		}
+		if loader.Get("pages/b.html", false) != b {
+			t.Error("Unaffected templates should not be reloaded")
+		}
+	})
+
+	h := watcher.Handler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.URL.Path == "/a" {
+			ExecuteTemplate(w, r, loader.Get("pages/a.html", false), nil)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	t.Run("Last good version should be kept on errors", func(t *testing.T) {
+		write("pages/a.html", "{{.Missing}")
+		if !eventually(func() bool { return len(watcher.Errors()) > 0 }) {
+			t.Fatal("Parse error should have been reported")
+		}
+		if got := render(t, loader.Get("pages/a.html", false)); got != "a2" {
+			t.Errorf("Expected last good version, got: %q", got)
+		}
+
+		if w := serve("/"); w.Code != http.StatusOK || w.Body.String() != "ok" {
+			t.Errorf("Reload errors should not affect other requests, got: "+
+				"%d %q", w.Code, w.Body.String())
+		}
+		if w := serve("/a"); w.Code != http.StatusInternalServerError ||
+			!strings.Contains(w.Body.String(), "pages/a.html") {
+			t.Errorf("Expected an error page for the broken template, got: "+
+				"%d %q", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Errors should be cleared once fixed", func(t *testing.T) {
+		write("pages/a.html", "a3")
+		if !eventually(func() bool { return len(watcher.Errors()) == 0 }) {
+			t.Fatal("Errors should have been cleared")
+		}
+
+		if w := serve("/a"); w.Code != http.StatusOK || w.Body.String() != "a3" {
+			t.Errorf("Expected the fixed template, got: %d %q", w.Code,
+				w.Body.String())
+		}
+	})
+}
+
+func TestTemplateWatcherRegistry(t *testing.T) {
+	dir := writeTemplates(t, registryTemplates)
+	registry := NewTemplateRegistry(filepath.Join(dir, "layout.html"),
+		filepath.Join(dir, "partials", "*.html")).
+		Register("home", filepath.Join(dir, "pages", "home.html"))
+
+	watcher, err := registry.Watch(dir)
+	if err != nil {
+		t.Fatalf("Unable to watch templates: %v", err)
+	}
+	defer watcher.Close()
+
+	var buf bytes.Buffer
+	registry.Get("home", false).Execute(&buf, "x")
+
+	if err := os.WriteFile(filepath.Join(dir, "partials", "nav.html"),
+		[]byte(`{{define "nav"}}<nav>new</nav>{{end}}`), 0600); err != nil {
+		t.Fatalf("Unable to write template: %v", err)
+	}
+	if !eventually(func() bool {
+		return strings.Contains(render(t, registry.Get("home", false)),
+			"<nav>new</nav>")
+	}) {
+		t.Error("Pages should be reloaded when partials change")
+	}
+}
+
+func TestTemplateWatcherHandlerPanics(t *testing.T) {
+	watcher, err := WatchTemplates(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unable to watch templates: %v", err)
+	}
+	defer watcher.Close()
+
+	h := watcher.Handler(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		GetTemplate(filepath.Join(t.TempDir(), "missing.html"), false)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError ||
+		!strings.Contains(w.Body.String(), "missing.html") {
+		t.Errorf("Expected an error page, got: %d %q", w.Code, w.Body.String())
+	}
+
+	passedOn := func(name string, h http.Handler) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s should be passed on", name)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	passedOn("Other panics", watcher.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+	passedOn("Template errors after the header", watcher.Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			GetTemplate(filepath.Join(t.TempDir(), "missing.html"), false)
+		})))
+}
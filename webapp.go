@@ -23,6 +23,13 @@ import (
 
 var templateCache sync.Map
 
+// defaultTemplateLoader loads templates for GetTemplate directly from the OS
+// file system.
+var defaultTemplateLoader = &TemplateLoader{
+	fsys:  osFS{},
+	cache: &templateCache,
+}
+
 // DetermineLocale tries to find the best locale for a given input from a list
 // of available locales. It applies the following logic:
 //    1. If the input can be directly found in list of available locales, it
@@ -30,6 +37,9 @@ var templateCache sync.Map
 //    2. If a less specific value is available, then it will be returned. E.g.
 //       if the input is zh-tw but zh is available then zh will be returned
 //    3. Otherwise, an empty string is returned.
+//
+// Locales are compared case-insensitively, the returned value is always the
+// one as it appears in available.
 func DetermineLocale(input string, available []string) string {
 	candidate := ""
 	for _, locale := range available {
@@ -57,12 +67,16 @@ func DetermineLocaleWithDefault(input string, available []string) string {
 	return candidate
 }
 
+// equalOrLessSpecific reports whether locale is either the same as input or a
+// less specific version of it. Language tags are case-insensitive, so the
+// comparison is done without regard to case.
 func equalOrLessSpecific(locale string, input string) bool {
 	prefix := locale
 	if len(input) > len(locale) {
 		prefix = prefix + "-"
 	}
-	return strings.HasPrefix(input, prefix)
+	return len(input) >= len(prefix) &&
+		strings.EqualFold(input[:len(prefix)], prefix)
 }
 
 // GetTemplate loads the template from the given path.
@@ -70,18 +84,22 @@ func equalOrLessSpecific(locale string, input string) bool {
 // The function caches the loaded template so that the same template would not
 // be parsed over and over again unless skipCache is set to true.
 //
-// Please note this method panics if template.ParseFiles fails in any way.
+// Functions registered with RegisterTemplateFuncs and
+// RegisterRequestTemplateFuncs are available to the template. To load
+// templates from an fs.FS, such as an embed.FS, please use a TemplateLoader
+// instead.
+//
+// Please note this method panics if the template cannot be parsed in any way,
+// please use LoadTemplate to handle the error instead.
 func GetTemplate(path string, skipCache bool) *template.Template {
-	tmpl, has := templateCache.Load(path)
-	if !has || skipCache {
-		tmpl = template.Must(template.ParseFiles(path))
-		templateCache.Store(path, tmpl)
-	}
-	return tmpl.(*template.Template)
+	return defaultTemplateLoader.Get(path, skipCache)
 }
 
 // HSTSHandler takes a normal HTTP handler and adds the capability of sending
 // HSTS headers.
+//
+// The header is always sent with a max-age of 2 years, includeSubDomains and
+// preload. Please use NewHSTSHandler for a configurable policy.
 func HSTSHandler(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Strict-Transport-Security",
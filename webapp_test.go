@@ -59,6 +59,14 @@ func TestDetermineLocale(t *testing.T) {
 	if DetermineLocale("zh-TW", available) != "zh-TW" {
 		t.Error("DetermineLocale(\"zh-TW\", available) should return \"zh-TW\"")
 	}
+
+	if DetermineLocale("zh-tw", available) != "zh-TW" {
+		t.Error("DetermineLocale(\"zh-tw\", available) should return \"zh-TW\"")
+	}
+
+	if DetermineLocale("EN-US", available) != "en-us" {
+		t.Error("DetermineLocale(\"EN-US\", available) should return \"en-us\"")
+	}
 }
 
 func TestDetermineLocaleWithDefault(t *testing.T) {